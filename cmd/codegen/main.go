@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"microgograd/codegen"
+	"microgograd/examples/manual_backprop"
+)
+
+func main() {
+	out := flag.String("o", "", "write the generated program to this file instead of stdout")
+	flag.Parse()
+
+	// Emit a runnable program for the manual backpropagation example graph:
+	//   go run cmd/codegen/main.go -o /tmp/model/main.go
+	//   go run /tmp/model/main.go 2 -3 10 -2
+	src, err := codegen.Generate(manual_backprop.Graph(), codegen.WithMain())
+	if err != nil {
+		log.Fatalf("Error generating code: %v", err)
+	}
+
+	if *out == "" {
+		fmt.Print(string(src))
+		return
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		log.Fatalf("Error writing %s: %v", *out, err)
+	}
+}
@@ -0,0 +1,410 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"microgograd/micrograd"
+)
+
+// Option configures the generated source.
+type Option func(*config)
+
+type config struct {
+	pkg  string
+	main bool
+}
+
+// WithPackage sets the package clause of the generated file. The default is
+// "model".
+func WithPackage(name string) Option {
+	return func(cfg *config) {
+		cfg.pkg = name
+	}
+}
+
+// WithMain generates a main package whose main function reads the inputs
+// from the command line and prints the value and gradient, so the file can
+// be run on its own with go run.
+func WithMain() Option {
+	return func(cfg *config) {
+		cfg.pkg = "main"
+		cfg.main = true
+	}
+}
+
+// reserved holds names the generated code uses itself. Predeclared
+// identifiers such as max are rejected as well, since shadowing them breaks
+// the generated code.
+var reserved = regexp.MustCompile(`^(v[0-9]+|adj|grad|math|fmt|os|strconv|float32|float64|Forward|Gradient|Inputs|main)$`)
+
+// supported lists the operations code can be generated for.
+var supported = map[micrograd.OperationEnum]bool{
+	micrograd.ADD: true, micrograd.SUB: true, micrograd.MUL: true, micrograd.DIV: true,
+	micrograd.POW: true, micrograd.NEG: true, micrograd.TANH: true, micrograd.RELU: true,
+	micrograd.SIGMOID: true, micrograd.EXP: true, micrograd.LOG: true, micrograd.SQRT: true,
+	micrograd.SUM: true, micrograd.PROD: true, micrograd.DOT: true,
+}
+
+// generator holds the state of a single Generate call.
+type generator[K micrograd.BaseNumeric] struct {
+	typ      string
+	bits     int
+	params   map[micrograd.Numeric[K]]int
+	temps    map[micrograd.Numeric[K]]int
+	need     map[micrograd.Numeric[K]]bool
+	names    []string
+	usesMath bool
+}
+
+// Generate emits a standalone Go source file that evaluates the graph rooted
+// at root without the autograd runtime. Named leaves become the parameters of
+// the generated Forward and Gradient functions, in topological order;
+// unnamed leaves are inlined as constants. Gradient returns the value
+// together with the analytic derivative with respect to each parameter,
+// following the requires-grad flags of the graph as Backward does.
+//
+// Leaf names must be valid Go identifiers and unique. Registered custom
+// operations are not supported.
+func Generate[K micrograd.BaseNumeric](root micrograd.Numeric[K], opts ...Option) ([]byte, error) {
+	cfg := &config{pkg: "model"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	g := &generator[K]{
+		typ:    fmt.Sprintf("%T", K(0)),
+		bits:   64,
+		params: make(map[micrograd.Numeric[K]]int),
+		temps:  make(map[micrograd.Numeric[K]]int),
+		need:   micrograd.Trainable(root),
+	}
+
+	if g.typ == "float32" {
+		g.bits = 32
+	}
+
+	nodes := micrograd.TopologicalOrder(root)
+	var interior []micrograd.Numeric[K]
+	for _, n := range nodes {
+		if len(n.GetChildren()) > 0 {
+			if !supported[n.GetOperation()] {
+				return nil, fmt.Errorf("cannot generate code for operation %q", n.GetOperation())
+			}
+			g.temps[n] = len(interior)
+			interior = append(interior, n)
+			continue
+		}
+
+		name := n.GetName()
+		if name == "" {
+			continue
+		}
+		if !token.IsIdentifier(name) || reserved.MatchString(name) || types.Universe.Lookup(name) != nil {
+			return nil, fmt.Errorf("leaf name %q is not usable as a Go identifier", name)
+		}
+		for _, seen := range g.names {
+			if seen == name {
+				return nil, fmt.Errorf("duplicate leaf name %q", name)
+			}
+		}
+		g.params[n] = len(g.names)
+		g.names = append(g.names, name)
+	}
+
+	var forward, backward bytes.Buffer
+	for _, n := range interior {
+		fmt.Fprintf(&forward, "v%d := %s\n", g.temps[n], g.forwardExpr(n))
+	}
+	for i := len(interior) - 1; i >= 0; i-- {
+		g.backwardStmts(&backward, interior[i])
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by microgograd codegen. DO NOT EDIT.\n\npackage %s\n\n", cfg.pkg)
+
+	var imports []string
+	if cfg.main {
+		imports = append(imports, `"fmt"`, `"os"`, `"strconv"`)
+	}
+	if g.usesMath {
+		imports = append(imports, `"math"`)
+	}
+	switch len(imports) {
+	case 0:
+	case 1:
+		fmt.Fprintf(&src, "import %s\n\n", imports[0])
+	default:
+		fmt.Fprintf(&src, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+
+	n := len(g.names)
+	params := ""
+	if n > 0 {
+		params = strings.Join(g.names, ", ") + " " + g.typ
+	}
+	quoted := make([]string, n)
+	for i, name := range g.names {
+		quoted[i] = strconv.Quote(name)
+	}
+
+	result := g.operand(root)
+	fmt.Fprintf(&src, "// Inputs lists the parameters of Forward and Gradient in order.\n")
+	fmt.Fprintf(&src, "var Inputs = [%d]string{%s}\n\n", n, strings.Join(quoted, ", "))
+
+	fmt.Fprintf(&src, "// Forward evaluates the graph.\n")
+	fmt.Fprintf(&src, "func Forward(%s) %s {\n%sreturn %s\n}\n\n", params, g.typ, forward.String(), result)
+
+	fmt.Fprintf(&src, "// Gradient evaluates the graph and its derivative with respect to each input.\n")
+	fmt.Fprintf(&src, "func Gradient(%s) (%s, [%d]%s) {\n", params, g.typ, n, g.typ)
+	fmt.Fprintf(&src, "%s", forward.String())
+	fmt.Fprintf(&src, "var grad [%d]%s\n", n, g.typ)
+	if len(interior) > 0 {
+		fmt.Fprintf(&src, "var adj [%d]%s\n", len(interior), g.typ)
+		fmt.Fprintf(&src, "adj[%d] = 1\n", g.temps[root])
+	} else if i, ok := g.params[root]; ok {
+		fmt.Fprintf(&src, "grad[%d] = 1\n", i)
+	}
+	fmt.Fprintf(&src, "%sreturn %s, grad\n}\n", backward.String(), result)
+
+	if cfg.main {
+		args := make([]string, n)
+		for i := range args {
+			args[i] = fmt.Sprintf("in[%d]", i)
+		}
+		fmt.Fprintf(&src, `
+func main() {
+	if len(os.Args) != %d {
+		fmt.Fprintf(os.Stderr, "usage: %%s %s\n", os.Args[0])
+		os.Exit(2)
+	}
+	var in [%d]%s
+	for i := range in {
+		x, err := strconv.ParseFloat(os.Args[i+1], %d)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%%s: %%v\n", Inputs[i], err)
+			os.Exit(2)
+		}
+		in[i] = %s(x)
+	}
+	value, grad := Gradient(%s)
+	fmt.Printf("value: %%v\n", value)
+	for i, name := range Inputs {
+		fmt.Printf("d/d%%s: %%v\n", name, grad[i])
+	}
+}
+`, n+1, strings.Join(g.names, " "), n, g.typ, g.bits, g.typ, strings.Join(args, ", "))
+	}
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+	return out, nil
+}
+
+// operand returns the expression referring to a node's value.
+func (g *generator[K]) operand(n micrograd.Numeric[K]) string {
+	if i, ok := g.temps[n]; ok {
+		return fmt.Sprintf("v%d", i)
+	}
+	if _, ok := g.params[n]; ok {
+		return n.GetName()
+	}
+	return g.literal(n.GetValue())
+}
+
+func (g *generator[K]) literal(x K) string {
+	f := float64(x)
+	switch {
+	case math.IsInf(f, 0):
+		g.usesMath = true
+		return fmt.Sprintf("%s(math.Inf(%d))", g.typ, int(math.Copysign(1, f)))
+	case math.IsNaN(f):
+		g.usesMath = true
+		return fmt.Sprintf("%s(math.NaN())", g.typ)
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, g.bits)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	if math.Signbit(f) {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// call wraps a float64 math function so it works for the target type.
+func (g *generator[K]) call(fn string, args ...string) string {
+	g.usesMath = true
+	if g.typ == "float64" {
+		return fmt.Sprintf("math.%s(%s)", fn, strings.Join(args, ", "))
+	}
+	wrapped := make([]string, len(args))
+	for i, a := range args {
+		wrapped[i] = "float64(" + a + ")"
+	}
+	return fmt.Sprintf("%s(math.%s(%s))", g.typ, fn, strings.Join(wrapped, ", "))
+}
+
+func (g *generator[K]) forwardExpr(n micrograd.Numeric[K]) string {
+	children := n.GetChildren()
+	c := make([]string, len(children))
+	for i, child := range children {
+		c[i] = g.operand(child)
+	}
+
+	switch n.GetOperation() {
+	case micrograd.ADD:
+		return c[0] + " + " + c[1]
+	case micrograd.SUB:
+		return c[0] + " - " + c[1]
+	case micrograd.MUL:
+		return c[0] + " * " + c[1]
+	case micrograd.DIV:
+		return c[0] + " / " + c[1]
+	case micrograd.POW:
+		return g.call("Pow", c[0], c[1])
+	case micrograd.NEG:
+		return "-" + c[0]
+	case micrograd.TANH:
+		return g.call("Tanh", c[0])
+	case micrograd.RELU:
+		return "max(" + c[0] + ", 0)"
+	case micrograd.SIGMOID:
+		return "1 / (1 + " + g.call("Exp", "-"+c[0]) + ")"
+	case micrograd.EXP:
+		return g.call("Exp", c[0])
+	case micrograd.LOG:
+		return g.call("Log", c[0])
+	case micrograd.SQRT:
+		return g.call("Sqrt", c[0])
+	case micrograd.SUM:
+		if len(c) == 0 {
+			return g.typ + "(0)"
+		}
+		return strings.Join(c, " + ")
+	case micrograd.PROD:
+		if len(c) == 0 {
+			return g.typ + "(1)"
+		}
+		return strings.Join(c, " * ")
+	case micrograd.DOT:
+		half := len(c) / 2
+		if half == 0 {
+			return g.typ + "(0)"
+		}
+		terms := make([]string, half)
+		for i := range terms {
+			terms[i] = c[i] + "*" + c[half+i]
+		}
+		return strings.Join(terms, " + ")
+	default:
+		panic(fmt.Sprintf("unsupported operation %q", n.GetOperation()))
+	}
+}
+
+// backwardStmts writes the statements accumulating the adjoint of n into its
+// children.
+func (g *generator[K]) backwardStmts(w *bytes.Buffer, n micrograd.Numeric[K]) {
+	if !g.need[n] {
+		return
+	}
+	children := n.GetChildren()
+	c := make([]string, len(children))
+	for i, child := range children {
+		c[i] = g.operand(child)
+	}
+	adj := fmt.Sprintf("adj[%d]", g.temps[n])
+	out := g.operand(n)
+
+	acc := func(i int, expr string) {
+		switch child := children[i]; {
+		case !g.need[child]:
+		case g.hasTemp(child):
+			fmt.Fprintf(w, "adj[%d] += %s\n", g.temps[child], expr)
+		case g.isParam(child):
+			fmt.Fprintf(w, "grad[%d] += %s\n", g.params[child], expr)
+		}
+	}
+
+	switch n.GetOperation() {
+	case micrograd.ADD:
+		acc(0, adj)
+		acc(1, adj)
+	case micrograd.SUB:
+		acc(0, adj)
+		acc(1, "-"+adj)
+	case micrograd.MUL:
+		acc(0, adj+" * "+c[1])
+		acc(1, adj+" * "+c[0])
+	case micrograd.DIV:
+		acc(0, adj+" / "+c[1])
+		acc(1, "-"+adj+" * "+c[0]+" / ("+c[1]+" * "+c[1]+")")
+	case micrograd.POW:
+		acc(0, adj+" * "+c[1]+" * "+g.call("Pow", c[0], c[1]+"-1"))
+		if g.receives(children[1]) {
+			fmt.Fprintf(w, "if %s > 0 {\n", c[0])
+			acc(1, adj+" * "+out+" * "+g.call("Log", c[0]))
+			fmt.Fprintf(w, "}\n")
+		}
+	case micrograd.NEG:
+		acc(0, "-"+adj)
+	case micrograd.TANH:
+		acc(0, adj+" * (1 - "+out+"*"+out+")")
+	case micrograd.RELU:
+		if g.receives(children[0]) {
+			fmt.Fprintf(w, "if %s > 0 {\n", c[0])
+			acc(0, adj)
+			fmt.Fprintf(w, "}\n")
+		}
+	case micrograd.SIGMOID:
+		acc(0, adj+" * "+out+" * (1 - "+out+")")
+	case micrograd.EXP:
+		acc(0, adj+" * "+out)
+	case micrograd.LOG:
+		acc(0, adj+" / "+c[0])
+	case micrograd.SQRT:
+		acc(0, adj+" / (2 * "+out+")")
+	case micrograd.SUM:
+		for i := range c {
+			acc(i, adj)
+		}
+	case micrograd.PROD:
+		for i := range c {
+			factors := []string{adj}
+			factors = append(factors, c[:i]...)
+			factors = append(factors, c[i+1:]...)
+			acc(i, strings.Join(factors, " * "))
+		}
+	case micrograd.DOT:
+		half := len(c) / 2
+		for i := 0; i < half; i++ {
+			acc(i, adj+" * "+c[half+i])
+			acc(half+i, adj+" * "+c[i])
+		}
+	}
+}
+
+// receives reports whether backwardStmts accumulates an adjoint into n.
+func (g *generator[K]) receives(n micrograd.Numeric[K]) bool {
+	return g.need[n] && (g.hasTemp(n) || g.isParam(n))
+}
+
+func (g *generator[K]) hasTemp(n micrograd.Numeric[K]) bool {
+	_, ok := g.temps[n]
+	return ok
+}
+
+func (g *generator[K]) isParam(n micrograd.Numeric[K]) bool {
+	_, ok := g.params[n]
+	return ok
+}
@@ -0,0 +1,163 @@
+package codegen
+
+import (
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"testing"
+
+	"microgograd/codegen/internal/testmodel"
+	"microgograd/micrograd"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "regenerate internal/testmodel")
+
+const goldenPath = "internal/testmodel/model.go"
+
+// testGraph exercises every supported operation, constants, a shared
+// subexpression and a stop-gradient node. Its leaves are returned in the generated parameter order.
+func testGraph() (micrograd.Numeric[float64], []*micrograd.Value[float64]) {
+	x := micrograd.NewValue(0.7, micrograd.WithName("x"))
+	y := micrograd.NewValue(1.3, micrograd.WithName("y"))
+	w := micrograd.NewValue(-0.4, micrograd.WithName("w"))
+
+	h := x.Mul(y).Sigmoid()
+	out := micrograd.Sum[float64](
+		h.Mul(h), h.Div(y), w.Tanh().Pow(2), micrograd.Prod[float64](x, y, w).Exp(),
+		x.PowValue(y), y.Sqrt().Log(), w.Neg().ReLU(), x.Sub(w), x.Add(micrograd.NewValue(-1.5)),
+		micrograd.Dot([]micrograd.Numeric[float64]{x, y}, []micrograd.Numeric[float64]{w, w}),
+		x.Mul(x.Mul(y).SetRequiresGrad(false)),
+	)
+	return out, []*micrograd.Value[float64]{x, y, w}
+}
+
+// typeCheck parses and type-checks generated source, returning its syntax
+// tree.
+func typeCheck(t *testing.T, src []byte) *ast.File {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "model.go", src, 0)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check(f.Name.Name, fset, []*ast.File{f}, nil)
+	assert.NoError(t, err)
+	return f
+}
+
+func TestGenerate_Golden(t *testing.T) {
+	root, _ := testGraph()
+	src, err := Generate(root, WithPackage("testmodel"))
+	assert.NoError(t, err)
+
+	if *update {
+		assert.NoError(t, os.WriteFile(goldenPath, src, 0644))
+	}
+	golden, err := os.ReadFile(goldenPath)
+	assert.NoError(t, err)
+	assert.Equal(t, string(golden), string(src), "run go test ./codegen -update to regenerate")
+	typeCheck(t, src)
+}
+
+func TestGenerated_MatchesBackward(t *testing.T) {
+	root, leaves := testGraph()
+	assert.Equal(t, [3]string{"x", "y", "w"}, testmodel.Inputs)
+
+	for _, point := range [][3]float64{{0.7, 1.3, -0.4}, {-0.2, 0.5, 0.9}, {1.1, 2.0, -1.5}} {
+		for i, leaf := range leaves {
+			leaf.SetValue(point[i])
+		}
+		root.Forward()
+		micrograd.ZeroGrad(root)
+		root.Backward()
+
+		assert.InDelta(t, root.GetValue(), testmodel.Forward(point[0], point[1], point[2]), 1e-12)
+		value, grad := testmodel.Gradient(point[0], point[1], point[2])
+		assert.InDelta(t, root.GetValue(), value, 1e-12)
+		for i, leaf := range leaves {
+			assert.InDelta(t, leaf.GetGradient(), grad[i], 1e-12, leaf.GetName())
+		}
+	}
+}
+
+func TestGenerate_Options(t *testing.T) {
+	t.Run("main package", func(t *testing.T) {
+		root, _ := testGraph()
+		src, err := Generate(root, WithMain())
+		assert.NoError(t, err)
+
+		f := typeCheck(t, src)
+		assert.Equal(t, "main", f.Name.Name)
+		assert.Contains(t, string(src), "func main() {")
+	})
+
+	t.Run("float32", func(t *testing.T) {
+		x := micrograd.NewValue[float32](0.5, micrograd.WithName("x"))
+		src, err := Generate(x.Tanh().Mul(micrograd.NewValue[float32](0.1)))
+		assert.NoError(t, err)
+
+		typeCheck(t, src)
+		assert.Contains(t, string(src), "func Forward(x float32) float32")
+		assert.Contains(t, string(src), "float32(math.Tanh(float64(x)))")
+		assert.Contains(t, string(src), "0.1")
+	})
+
+	t.Run("leaf root", func(t *testing.T) {
+		x := micrograd.NewValue(0.5, micrograd.WithName("x"))
+		src, err := Generate[float64](x)
+		assert.NoError(t, err)
+		assert.Contains(t, string(src), "grad[0] = 1")
+	})
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		root func() micrograd.Numeric[float64]
+	}{
+		{"invalid identifier", func() micrograd.Numeric[float64] {
+			return micrograd.NewValue(1.0, micrograd.WithName("not valid")).Tanh()
+		}},
+		{"reserved name", func() micrograd.Numeric[float64] {
+			return micrograd.NewValue(1.0, micrograd.WithName("v0")).Tanh()
+		}},
+		{"predeclared identifier", func() micrograd.Numeric[float64] {
+			return micrograd.NewValue(1.0, micrograd.WithName("max")).ReLU()
+		}},
+		{"keyword", func() micrograd.Numeric[float64] {
+			return micrograd.NewValue(1.0, micrograd.WithName("func")).Tanh()
+		}},
+		{"duplicate names", func() micrograd.Numeric[float64] {
+			a := micrograd.NewValue(1.0, micrograd.WithName("a"))
+			b := micrograd.NewValue(2.0, micrograd.WithName("a"))
+			return a.Add(b)
+		}},
+		{"custom operation", func() micrograd.Numeric[float64] {
+			return micrograd.Apply[float64](double{}, micrograd.NewValue(1.0))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(tt.root())
+			assert.Error(t, err)
+		})
+	}
+}
+
+type double struct{}
+
+func (double) Name() string                           { return "codegen.double" }
+func (double) Symbol() string                         { return "2x" }
+func (double) Arity() int                             { return 1 }
+func (double) Forward(in []float64) float64           { return 2 * in[0] }
+func (double) Gradients([]float64, float64) []float64 { return []float64{2} }
+
+var _ = micrograd.MustRegisterOp[float64](double{})
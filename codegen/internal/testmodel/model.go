@@ -0,0 +1,101 @@
+// Code generated by microgograd codegen. DO NOT EDIT.
+
+package testmodel
+
+import "math"
+
+// Inputs lists the parameters of Forward and Gradient in order.
+var Inputs = [3]string{"x", "y", "w"}
+
+// Forward evaluates the graph.
+func Forward(x, y, w float64) float64 {
+	v0 := x * y
+	v1 := 1 / (1 + math.Exp(-v0))
+	v2 := v1 * v1
+	v3 := v1 / y
+	v4 := math.Tanh(w)
+	v5 := math.Pow(v4, 2.0)
+	v6 := x * y * w
+	v7 := math.Exp(v6)
+	v8 := math.Pow(x, y)
+	v9 := math.Sqrt(y)
+	v10 := math.Log(v9)
+	v11 := -w
+	v12 := max(v11, 0)
+	v13 := x - w
+	v14 := x + (-1.5)
+	v15 := x*w + y*w
+	v16 := x * y
+	v17 := x * v16
+	v18 := v2 + v3 + v5 + v7 + v8 + v10 + v12 + v13 + v14 + v15 + v17
+	return v18
+}
+
+// Gradient evaluates the graph and its derivative with respect to each input.
+func Gradient(x, y, w float64) (float64, [3]float64) {
+	v0 := x * y
+	v1 := 1 / (1 + math.Exp(-v0))
+	v2 := v1 * v1
+	v3 := v1 / y
+	v4 := math.Tanh(w)
+	v5 := math.Pow(v4, 2.0)
+	v6 := x * y * w
+	v7 := math.Exp(v6)
+	v8 := math.Pow(x, y)
+	v9 := math.Sqrt(y)
+	v10 := math.Log(v9)
+	v11 := -w
+	v12 := max(v11, 0)
+	v13 := x - w
+	v14 := x + (-1.5)
+	v15 := x*w + y*w
+	v16 := x * y
+	v17 := x * v16
+	v18 := v2 + v3 + v5 + v7 + v8 + v10 + v12 + v13 + v14 + v15 + v17
+	var grad [3]float64
+	var adj [19]float64
+	adj[18] = 1
+	adj[2] += adj[18]
+	adj[3] += adj[18]
+	adj[5] += adj[18]
+	adj[7] += adj[18]
+	adj[8] += adj[18]
+	adj[10] += adj[18]
+	adj[12] += adj[18]
+	adj[13] += adj[18]
+	adj[14] += adj[18]
+	adj[15] += adj[18]
+	adj[17] += adj[18]
+	grad[0] += adj[17] * v16
+	grad[0] += adj[15] * w
+	grad[2] += adj[15] * x
+	grad[1] += adj[15] * w
+	grad[2] += adj[15] * y
+	grad[0] += adj[14]
+	grad[0] += adj[13]
+	grad[2] += -adj[13]
+	if v11 > 0 {
+		adj[11] += adj[12]
+	}
+	grad[2] += -adj[11]
+	adj[9] += adj[10] / v9
+	grad[1] += adj[9] / (2 * v9)
+	grad[0] += adj[8] * y * math.Pow(x, y-1)
+	if x > 0 {
+		grad[1] += adj[8] * v8 * math.Log(x)
+	}
+	adj[6] += adj[7] * v7
+	grad[0] += adj[6] * y * w
+	grad[1] += adj[6] * x * w
+	grad[2] += adj[6] * x * y
+	adj[4] += adj[5] * 2.0 * math.Pow(v4, 2.0-1)
+	grad[2] += adj[4] * (1 - v4*v4)
+	adj[1] += adj[3] / y
+	grad[1] += -adj[3] * v1 / (y * y)
+	adj[1] += adj[2] * v1
+	adj[1] += adj[2] * v1
+	adj[0] += adj[1] * v1 * (1 - v1)
+	grad[0] += adj[0] * y
+	grad[1] += adj[0] * x
+	return v18, grad
+}
@@ -7,8 +7,8 @@ import (
 	"microgograd/plot"
 )
 
-func Run() error {
-	// Create a simple computation graph
+// Graph builds the example computation graph L = (a*b + c) * f.
+func Graph() *micrograd.Value[float64] {
 	a := micrograd.NewValue(2.0, micrograd.WithName("a"))
 	b := micrograd.NewValue(-3.0, micrograd.WithName("b"))
 	c := micrograd.NewValue(10.0, micrograd.WithName("c"))
@@ -16,11 +16,16 @@ func Run() error {
 	e := a.Mul(b).SetName("e")
 	d := e.Add(c).SetName("d")
 
-	f := micrograd.NewValue(-2.0).SetName("f")
-	L := d.Mul(f).SetName("L")
+	// f is a fixed coefficient rather than a parameter, so backpropagation
+	// leaves its gradient at zero.
+	f := micrograd.NewValue(-2.0, micrograd.WithName("f"), micrograd.WithRequiresGrad(false))
+	return d.Mul(f).SetName("L")
+}
 
-	L.SetGradient(1.0)
-	L.Backtrack()
+func Run() error {
+	// Create a simple computation graph
+	L := Graph()
+	L.Backward()
 
 	// Generate interactive HTML version
 	err := plot.WriteInteractiveHTML(L, "graph.html")
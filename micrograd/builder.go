@@ -0,0 +1,96 @@
+package micrograd
+
+// Builder applies operations to values. Writing a model against a Builder
+// lets the caller decide, per call, whether running it records a graph for
+// backpropagation (Graph) or only computes values (Inference). The choice
+// travels with the builder rather than living in global state, so goroutines
+// training and evaluating models at the same time do not affect each other.
+type Builder[K BaseNumeric] interface {
+	Add(a, b Numeric[K]) Numeric[K]
+	Sub(a, b Numeric[K]) Numeric[K]
+	Mul(a, b Numeric[K]) Numeric[K]
+	Div(a, b Numeric[K]) Numeric[K]
+	Pow(a Numeric[K], exponent K) Numeric[K]
+	PowValue(a, exponent Numeric[K]) Numeric[K]
+	Neg(a Numeric[K]) Numeric[K]
+	Tanh(a Numeric[K]) Numeric[K]
+	ReLU(a Numeric[K]) Numeric[K]
+	Sigmoid(a Numeric[K]) Numeric[K]
+	Exp(a Numeric[K]) Numeric[K]
+	Log(a Numeric[K]) Numeric[K]
+	Sqrt(a Numeric[K]) Numeric[K]
+	Sum(values ...Numeric[K]) Numeric[K]
+	Prod(values ...Numeric[K]) Numeric[K]
+	Dot(ws, xs []Numeric[K]) Numeric[K]
+	Apply(op Op[K], inputs ...Numeric[K]) Numeric[K]
+}
+
+var (
+	_ Builder[float64] = Graph[float64]{}
+	_ Builder[float64] = Inference[float64]{}
+)
+
+// Graph is the Builder that records every operation as a node, exactly like
+// calling the operations on values directly.
+type Graph[K BaseNumeric] struct{}
+
+func (Graph[K]) Add(a, b Numeric[K]) Numeric[K]             { return a.Add(b) }
+func (Graph[K]) Sub(a, b Numeric[K]) Numeric[K]             { return a.Sub(b) }
+func (Graph[K]) Mul(a, b Numeric[K]) Numeric[K]             { return a.Mul(b) }
+func (Graph[K]) Div(a, b Numeric[K]) Numeric[K]             { return a.Div(b) }
+func (Graph[K]) Pow(a Numeric[K], exponent K) Numeric[K]    { return a.Pow(exponent) }
+func (Graph[K]) PowValue(a, exponent Numeric[K]) Numeric[K] { return a.PowValue(exponent) }
+func (Graph[K]) Neg(a Numeric[K]) Numeric[K]                { return a.Neg() }
+func (Graph[K]) Tanh(a Numeric[K]) Numeric[K]               { return a.Tanh() }
+func (Graph[K]) ReLU(a Numeric[K]) Numeric[K]               { return a.ReLU() }
+func (Graph[K]) Sigmoid(a Numeric[K]) Numeric[K]            { return a.Sigmoid() }
+func (Graph[K]) Exp(a Numeric[K]) Numeric[K]                { return a.Exp() }
+func (Graph[K]) Log(a Numeric[K]) Numeric[K]                { return a.Log() }
+func (Graph[K]) Sqrt(a Numeric[K]) Numeric[K]               { return a.Sqrt() }
+func (Graph[K]) Sum(values ...Numeric[K]) Numeric[K]        { return Sum(values...) }
+func (Graph[K]) Prod(values ...Numeric[K]) Numeric[K]       { return Prod(values...) }
+func (Graph[K]) Dot(ws, xs []Numeric[K]) Numeric[K]         { return Dot(ws, xs) }
+
+func (Graph[K]) Apply(op Op[K], inputs ...Numeric[K]) Numeric[K] {
+	return Apply(op, inputs...)
+}
+
+// Inference is the Builder for running a trained model: operations compute
+// their values but return constant leaves instead of recording their
+// children, so no graph is built and nothing can be backpropagated.
+type Inference[K BaseNumeric] struct{}
+
+func (Inference[K]) Add(a, b Numeric[K]) Numeric[K] { return infer(ADD, a, b) }
+func (Inference[K]) Sub(a, b Numeric[K]) Numeric[K] { return infer(SUB, a, b) }
+func (Inference[K]) Mul(a, b Numeric[K]) Numeric[K] { return infer(MUL, a, b) }
+func (Inference[K]) Div(a, b Numeric[K]) Numeric[K] { return infer(DIV, a, b) }
+
+func (Inference[K]) Pow(a Numeric[K], exponent K) Numeric[K] {
+	return infer[K](POW, a, &Value[K]{datum: exponent, noGrad: true})
+}
+
+func (Inference[K]) PowValue(a, exponent Numeric[K]) Numeric[K] { return infer(POW, a, exponent) }
+func (Inference[K]) Neg(a Numeric[K]) Numeric[K]                { return infer(NEG, a) }
+func (Inference[K]) Tanh(a Numeric[K]) Numeric[K]               { return infer(TANH, a) }
+func (Inference[K]) ReLU(a Numeric[K]) Numeric[K]               { return infer(RELU, a) }
+func (Inference[K]) Sigmoid(a Numeric[K]) Numeric[K]            { return infer(SIGMOID, a) }
+func (Inference[K]) Exp(a Numeric[K]) Numeric[K]                { return infer(EXP, a) }
+func (Inference[K]) Log(a Numeric[K]) Numeric[K]                { return infer(LOG, a) }
+func (Inference[K]) Sqrt(a Numeric[K]) Numeric[K]               { return infer(SQRT, a) }
+func (Inference[K]) Sum(values ...Numeric[K]) Numeric[K]        { return infer(SUM, values...) }
+func (Inference[K]) Prod(values ...Numeric[K]) Numeric[K]       { return infer(PROD, values...) }
+
+func (Inference[K]) Dot(ws, xs []Numeric[K]) Numeric[K] {
+	checkDot(ws, xs)
+	return infer(DOT, append(append(make([]Numeric[K], 0, 2*len(ws)), ws...), xs...)...)
+}
+
+func (Inference[K]) Apply(op Op[K], inputs ...Numeric[K]) Numeric[K] {
+	return infer(checkApply(op, len(inputs)), inputs...)
+}
+
+// infer computes an operation into a constant leaf that keeps no reference
+// to children.
+func infer[K BaseNumeric](op OperationEnum, children ...Numeric[K]) Numeric[K] {
+	return &Value[K]{datum: evaluate(op, children), noGrad: true}
+}
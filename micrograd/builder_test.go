@@ -0,0 +1,106 @@
+package micrograd
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInference(t *testing.T) {
+	t.Run("values without a graph", func(t *testing.T) {
+		x := NewValue(0.5, WithName("x"))
+		w := NewValue(-1.5, WithName("w"))
+		model := func(b Builder[float64]) Numeric[float64] {
+			return b.Tanh(b.Add(b.Mul(x, w), b.Pow(x, 2)))
+		}
+		want := model(Graph[float64]{})
+
+		out := model(Inference[float64]{})
+
+		assert.Equal(t, want.GetValue(), out.GetValue())
+		assert.NotEmpty(t, want.GetChildren())
+		assert.Empty(t, out.GetChildren())
+		assert.Equal(t, OperationEnum(UNSET), out.GetOperation())
+		assert.False(t, out.RequiresGrad())
+
+		out.Backward()
+		assert.Equal(t, 0.0, x.GetGradient())
+		assert.Equal(t, 0.0, w.GetGradient())
+	})
+
+	t.Run("n-ary and registered operations", func(t *testing.T) {
+		a := NewValue(3.0)
+		b := NewValue(4.0)
+		var inf Inference[float64]
+
+		assert.Equal(t, 7.0, inf.Sum(a, b).GetValue())
+		assert.Empty(t, inf.Sum(a, b).GetChildren())
+		assert.Equal(t, 12.0, inf.Dot([]Numeric[float64]{a}, []Numeric[float64]{b}).GetValue())
+		h := inf.Apply(hypot{}, a, b)
+		assert.Equal(t, 5.0, h.GetValue())
+		assert.Empty(t, h.GetChildren())
+
+		assert.Panics(t, func() { inf.Dot([]Numeric[float64]{a}, nil) })
+		assert.Panics(t, func() { inf.Apply(hypot{}, a) })
+	})
+
+	t.Run("other goroutines keep recording", func(t *testing.T) {
+		x := NewValue(2.0)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				Inference[float64]{}.Mul(x, x)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				assert.Len(t, x.Mul(x).GetChildren(), 2)
+			}
+		}()
+		wg.Wait()
+	})
+}
+
+func BenchmarkInference(b *testing.B) {
+	const inputs, hidden = 64, 32
+	xs := make([]Numeric[float64], inputs)
+	for i := range xs {
+		xs[i] = NewValue(float64(i%7)/7 - 0.5)
+	}
+	ws := make([][]Numeric[float64], hidden)
+	for j := range ws {
+		ws[j] = make([]Numeric[float64], inputs)
+		for i := range ws[j] {
+			ws[j][i] = NewValue(float64((i*31+j*17)%13)/13 - 0.5)
+		}
+	}
+	model := func(bd Builder[float64]) Numeric[float64] {
+		var out Numeric[float64] = NewValue(0.0)
+		for j := range ws {
+			var acc Numeric[float64] = NewValue(0.1)
+			for i, x := range xs {
+				acc = bd.Add(acc, bd.Mul(ws[j][i], x))
+			}
+			out = bd.Add(out, bd.Tanh(acc))
+		}
+		return out
+	}
+
+	b.Run("graph", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			model(Graph[float64]{})
+		}
+	})
+
+	b.Run("inference", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			model(Inference[float64]{})
+		}
+	})
+}
@@ -0,0 +1,284 @@
+package micrograd
+
+import (
+	"fmt"
+	"math"
+)
+
+// Program is a graph compiled into a linear instruction tape. Every node owns
+// one slot in a flat slab of K, leaves first, so evaluation and
+// differentiation walk plain slices instead of pointer-linked Values. Eval
+// and Grad reuse the program's buffers and do not allocate, apart from any
+// allocation made by registered custom operations.
+//
+// A Program is not safe for concurrent use.
+type Program[K BaseNumeric] struct {
+	inputs  []Numeric[K]
+	frozen  []int32
+	tape    []instruction
+	args    []int32
+	customs []Op[K]
+	root    int32
+
+	values  []K
+	grads   []K
+	scratch []K
+}
+
+// instruction computes slot out from the operand slots args[start:start+n].
+// The reverse sweep skips it when no gradient needs to flow through it.
+type instruction struct {
+	op     OperationEnum
+	out    int32
+	start  int32
+	n      int32
+	custom int32
+	skip   bool
+}
+
+// Compile flattens the graph rooted at root into a Program. The leaves of the
+// graph become the program's inputs, in the order reported by Inputs, except
+// for unnamed constants such as the exponent of Pow, whose values are baked
+// into the program. Gradients follow the requires-grad flags as they stand
+// at compile time: frozen inputs get a zero gradient and nothing flows
+// through nodes cleared with SetRequiresGrad(false), as with Backward.
+func Compile[K BaseNumeric](root Numeric[K]) (*Program[K], error) {
+	nodes := TopologicalOrder(root)
+	need := trainable(nodes)
+	slots := make(map[Numeric[K]]int32, len(nodes))
+	p := &Program[K]{}
+
+	var constants []Numeric[K]
+	for _, n := range nodes {
+		if len(n.GetChildren()) > 0 {
+			continue
+		}
+		if isConstant(n) {
+			constants = append(constants, n)
+			continue
+		}
+		slots[n] = int32(len(p.inputs))
+		if !need[n] {
+			p.frozen = append(p.frozen, slots[n])
+		}
+		p.inputs = append(p.inputs, n)
+	}
+
+	next := int32(len(p.inputs))
+	for _, n := range constants {
+		slots[n] = next
+		next++
+	}
+
+	widest := 0
+	for _, n := range nodes {
+		children := n.GetChildren()
+		if len(children) == 0 {
+			continue
+		}
+
+		ins := instruction{
+			op:     n.GetOperation(),
+			out:    next,
+			start:  int32(len(p.args)),
+			n:      int32(len(children)),
+			custom: -1,
+		}
+		if ins.op >= firstCustomOp {
+			op, ok := LookupOp[K](ins.op)
+			if !ok {
+				return nil, fmt.Errorf("cannot compile unknown operation %q", ins.op)
+			}
+			ins.custom = int32(len(p.customs))
+			p.customs = append(p.customs, op)
+		}
+		for _, c := range children {
+			p.args = append(p.args, slots[c])
+		}
+		widest = max(widest, len(children))
+
+		slots[n] = next
+		next++
+		ins.skip = !need[n]
+		p.tape = append(p.tape, ins)
+	}
+
+	p.root = slots[root]
+	p.values = make([]K, next)
+	p.grads = make([]K, next)
+	p.scratch = make([]K, widest+1)
+	for _, n := range constants {
+		p.values[slots[n]] = n.GetValue()
+	}
+	return p, nil
+}
+
+// Inputs returns the leaves of the compiled graph, other than constants, in
+// the order Eval and Grad expect their values.
+func (p *Program[K]) Inputs() []Numeric[K] {
+	return append([]Numeric[K]{}, p.inputs...)
+}
+
+// Eval runs the forward sweep for the given input values and returns the
+// value of the root. It panics if len(inputs) does not match Inputs.
+func (p *Program[K]) Eval(inputs []K) K {
+	if len(inputs) != len(p.inputs) {
+		panic(fmt.Sprintf("program takes %d inputs, got %d", len(p.inputs), len(inputs)))
+	}
+	copy(p.values, inputs)
+	p.forward()
+	return p.values[p.root]
+}
+
+// Grad runs the forward and reverse sweeps and returns the value of the root
+// together with its gradient with respect to each input. The gradient slice
+// is owned by the program and overwritten by the next call to Grad.
+func (p *Program[K]) Grad(inputs []K) (K, []K) {
+	out := p.Eval(inputs)
+	clear(p.grads)
+	p.grads[p.root] = 1
+	p.backward()
+	for _, i := range p.frozen {
+		p.grads[i] = 0
+	}
+	return out, p.grads[:len(p.inputs)]
+}
+
+func (p *Program[K]) forward() {
+	v := p.values
+	for _, ins := range p.tape {
+		args := p.args[ins.start : ins.start+ins.n]
+
+		var out K
+		switch ins.op {
+		case ADD:
+			out = v[args[0]] + v[args[1]]
+		case SUB:
+			out = v[args[0]] - v[args[1]]
+		case MUL:
+			out = v[args[0]] * v[args[1]]
+		case DIV:
+			out = v[args[0]] / v[args[1]]
+		case POW:
+			out = K(math.Pow(float64(v[args[0]]), float64(v[args[1]])))
+		case NEG:
+			out = -v[args[0]]
+		case TANH:
+			out = K(math.Tanh(float64(v[args[0]])))
+		case RELU:
+			out = max(v[args[0]], 0)
+		case SIGMOID:
+			out = K(1 / (1 + math.Exp(-float64(v[args[0]]))))
+		case EXP:
+			out = K(math.Exp(float64(v[args[0]])))
+		case LOG:
+			out = K(math.Log(float64(v[args[0]])))
+		case SQRT:
+			out = K(math.Sqrt(float64(v[args[0]])))
+		case SUM:
+			for _, a := range args {
+				out += v[a]
+			}
+		case PROD:
+			out = 1
+			for _, a := range args {
+				out *= v[a]
+			}
+		case DOT:
+			half := len(args) / 2
+			for i := 0; i < half; i++ {
+				out += v[args[i]] * v[args[half+i]]
+			}
+		default:
+			out = p.customs[ins.custom].Forward(p.gather(args))
+		}
+		v[ins.out] = out
+	}
+}
+
+func (p *Program[K]) backward() {
+	v, grads := p.values, p.grads
+	for i := len(p.tape) - 1; i >= 0; i-- {
+		ins := p.tape[i]
+		if ins.skip {
+			continue
+		}
+		args := p.args[ins.start : ins.start+ins.n]
+		g, out := grads[ins.out], v[ins.out]
+
+		switch ins.op {
+		case ADD:
+			grads[args[0]] += g
+			grads[args[1]] += g
+		case SUB:
+			grads[args[0]] += g
+			grads[args[1]] -= g
+		case MUL:
+			a, b := v[args[0]], v[args[1]]
+			grads[args[0]] += g * b
+			grads[args[1]] += g * a
+		case DIV:
+			a, b := v[args[0]], v[args[1]]
+			grads[args[0]] += g / b
+			grads[args[1]] -= g * a / (b * b)
+		case POW:
+			base, exponent := float64(v[args[0]]), float64(v[args[1]])
+			grads[args[0]] += g * K(exponent*math.Pow(base, exponent-1))
+			if base > 0 {
+				grads[args[1]] += g * out * K(math.Log(base))
+			}
+		case NEG:
+			grads[args[0]] -= g
+		case TANH:
+			grads[args[0]] += g * (1 - out*out)
+		case RELU:
+			if v[args[0]] > 0 {
+				grads[args[0]] += g
+			}
+		case SIGMOID:
+			grads[args[0]] += g * out * (1 - out)
+		case EXP:
+			grads[args[0]] += g * out
+		case LOG:
+			grads[args[0]] += g / v[args[0]]
+		case SQRT:
+			grads[args[0]] += g / (2 * out)
+		case SUM:
+			for _, a := range args {
+				grads[a] += g
+			}
+		case PROD:
+			suffix := p.scratch[:len(args)+1]
+			suffix[len(args)] = 1
+			for j := len(args) - 1; j >= 0; j-- {
+				suffix[j] = suffix[j+1] * v[args[j]]
+			}
+			prefix := K(1)
+			for j, a := range args {
+				grads[a] += g * prefix * suffix[j+1]
+				prefix *= v[a]
+			}
+		case DOT:
+			half := len(args) / 2
+			for j := 0; j < half; j++ {
+				w, x := args[j], args[half+j]
+				grads[w] += g * v[x]
+				grads[x] += g * v[w]
+			}
+		default:
+			local := p.customs[ins.custom].Gradients(p.gather(args), out)
+			for j, a := range args {
+				grads[a] += g * local[j]
+			}
+		}
+	}
+}
+
+// gather copies the operand values of a custom operation into scratch space.
+func (p *Program[K]) gather(args []int32) []K {
+	in := p.scratch[:len(args)]
+	for i, a := range args {
+		in[i] = p.values[a]
+	}
+	return in
+}
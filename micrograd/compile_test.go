@@ -0,0 +1,191 @@
+package micrograd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mlp builds a two-layer perceptron with a squared-error loss, returning the
+// loss and the leaves it was built from.
+func mlp(inputs, hidden int) (Numeric[float64], []*Value[float64]) {
+	var leaves []*Value[float64]
+	leaf := func(x float64) *Value[float64] {
+		v := NewValue(x)
+		leaves = append(leaves, v)
+		return v
+	}
+
+	xs := make([]Numeric[float64], inputs)
+	for i := range xs {
+		xs[i] = leaf(float64(i%7)/7 - 0.5)
+	}
+	hs := make([]Numeric[float64], hidden)
+	for j := range hs {
+		ws := make([]Numeric[float64], inputs)
+		for i := range ws {
+			ws[i] = leaf(float64((i*31+j*17)%13)/13 - 0.5)
+		}
+		hs[j] = Dot(ws, xs).Add(leaf(0.1)).Tanh()
+	}
+	outW := make([]Numeric[float64], hidden)
+	for j := range outW {
+		outW[j] = leaf(float64(j%5)/5 - 0.4)
+	}
+	out := Dot(outW, hs).Sigmoid()
+	return out.Sub(leaf(1)).Pow(2), leaves
+}
+
+func TestCompile(t *testing.T) {
+	t.Run("matches Forward and Backward", func(t *testing.T) {
+		a := NewValue(0.7, WithName("a"))
+		b := NewValue(1.3, WithName("b"))
+		c := NewValue(-0.4, WithName("c"))
+		frozen := NewValue(0.9, WithName("frozen"), WithRequiresGrad(false))
+		y := a.Mul(b).Sigmoid()
+		out := Sum[float64](
+			y.Mul(y), y.Div(b), c.Tanh().Pow(2), Prod[float64](a, b, c).Exp(),
+			a.PowValue(b), b.Sqrt().Log(), c.Neg().ReLU(), a.Sub(c), a.Add(c),
+			Dot([]Numeric[float64]{a, b}, []Numeric[float64]{c, c}),
+			a.Pow(3), a.Mul(a.Mul(b).SetRequiresGrad(false)), frozen.Mul(b),
+		)
+
+		p, err := Compile(out)
+		assert.NoError(t, err)
+
+		inputs := p.Inputs()
+		assert.ElementsMatch(t, []Numeric[float64]{a, b, c, frozen}, inputs, "constants are baked in")
+		values := make([]float64, len(inputs))
+		for i, in := range inputs {
+			values[i] = in.GetValue()
+		}
+
+		out.Backward()
+		value, grads := p.Grad(values)
+		assert.InDelta(t, out.GetValue(), value, 1e-12)
+		for i, in := range inputs {
+			assert.InDelta(t, in.GetGradient(), grads[i], 1e-12)
+		}
+	})
+
+	t.Run("new input values", func(t *testing.T) {
+		x := NewValue(1.0, WithName("x"))
+		y := NewValue(2.0, WithName("y"))
+		out := x.Mul(y).Add(x)
+
+		p, err := Compile(out)
+		assert.NoError(t, err)
+		assert.Equal(t, []Numeric[float64]{x, y}, p.Inputs())
+
+		assert.Equal(t, 3.0, p.Eval([]float64{1, 2}))
+		assert.Equal(t, 15.0, p.Eval([]float64{3, 4}))
+
+		value, grads := p.Grad([]float64{3, 4})
+		assert.Equal(t, 15.0, value)
+		assert.Equal(t, []float64{5, 3}, grads)
+
+		assert.Equal(t, 1.0, x.GetValue(), "leaves are not modified")
+	})
+
+	t.Run("shared subexpressions", func(t *testing.T) {
+		x := NewValue(3.0)
+		y := x.Mul(x)
+		p, err := Compile(y.Add(y))
+		assert.NoError(t, err)
+
+		_, grads := p.Grad([]float64{3})
+		assert.Equal(t, []float64{12}, grads)
+	})
+
+	t.Run("leaf root", func(t *testing.T) {
+		x := NewValue(3.0)
+		p, err := Compile[float64](x)
+		assert.NoError(t, err)
+
+		value, grads := p.Grad([]float64{5})
+		assert.Equal(t, 5.0, value)
+		assert.Equal(t, []float64{1}, grads)
+	})
+
+	t.Run("custom operations", func(t *testing.T) {
+		a := NewValue(3.0)
+		b := NewValue(4.0)
+		out := Apply[float64](logSumExp{}, Apply[float64](hypot{}, a, b), a)
+		out.Backward()
+
+		p, err := Compile(out)
+		assert.NoError(t, err)
+		value, grads := p.Grad([]float64{3, 4})
+		assert.InDelta(t, out.GetValue(), value, 1e-12)
+		assert.InDelta(t, a.GetGradient(), grads[0], 1e-12)
+		assert.InDelta(t, b.GetGradient(), grads[1], 1e-12)
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		a := NewValue(3.0)
+		out := &Value[float64]{operation: firstCustomOp + 0xfff, children: []Numeric[float64]{a}}
+
+		_, err := Compile[float64](out)
+		assert.Error(t, err)
+	})
+
+	t.Run("wrong number of inputs", func(t *testing.T) {
+		p, err := Compile(NewValue(1.0).Add(NewValue(2.0)))
+		assert.NoError(t, err)
+		assert.Panics(t, func() { p.Eval([]float64{1}) })
+	})
+
+	t.Run("float32", func(t *testing.T) {
+		x := NewValue[float32](0.5)
+		out := x.Mul(x).Mul(x).Tanh()
+		p, err := Compile(out)
+		assert.NoError(t, err)
+
+		out.Backward()
+		_, grads := p.Grad([]float32{0.5})
+		assert.InDelta(t, x.GetGradient(), grads[0], 1e-6)
+	})
+}
+
+func TestCompile_NoAllocations(t *testing.T) {
+	loss, _ := mlp(8, 4)
+	p, err := Compile(loss)
+	assert.NoError(t, err)
+
+	inputs := make([]float64, len(p.Inputs()))
+	for i, in := range p.Inputs() {
+		inputs[i] = in.GetValue()
+	}
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() { p.Eval(inputs) }))
+	assert.Zero(t, testing.AllocsPerRun(100, func() { p.Grad(inputs) }))
+}
+
+func BenchmarkMLP(b *testing.B) {
+	loss, _ := mlp(64, 32)
+
+	b.Run("graph", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			loss.Forward()
+			ZeroGrad(loss)
+			loss.Backward()
+		}
+	})
+
+	b.Run("program", func(b *testing.B) {
+		p, err := Compile(loss)
+		if err != nil {
+			b.Fatal(err)
+		}
+		inputs := make([]float64, len(p.Inputs()))
+		for i, in := range p.Inputs() {
+			inputs[i] = in.GetValue()
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			p.Grad(inputs)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package micrograd
+
+import (
+	"math"
+	"slices"
+	"strconv"
+)
+
+// CSE returns an equivalent graph computing root in which structurally
+// identical nodes are merged into one shared node. Two interior nodes are
+// identical when they apply the same operation to the same children, in any
+// order for commutative operations, and agree on whether they require
+// gradients; unnamed constants are identical when they hold the same value.
+// Backpropagation accumulates the gradient of a shared node from all of its
+// uses, so gradients are unchanged while each subexpression is computed once.
+//
+// As with Simplify, the original graph is left untouched: interior nodes are
+// rebuilt and other leaves are shared with it. A merged node keeps the name
+// of the first occurrence in topological order. Nodes with hooks are never
+// merged, since their hooks would then see the gradient of every use; they
+// are rebuilt with the same hooks instead.
+func CSE[K BaseNumeric](root Numeric[K]) Numeric[K] {
+	nodes := TopologicalOrder(root)
+	merged := make(map[Numeric[K]]Numeric[K], len(nodes))
+	table := make(map[string]Numeric[K])
+	ids := make(map[Numeric[K]]int)
+	id := func(n Numeric[K]) int {
+		if i, ok := ids[n]; ok {
+			return i
+		}
+		ids[n] = len(ids)
+		return ids[n]
+	}
+
+	for _, n := range nodes {
+		children := n.GetChildren()
+		if len(children) == 0 {
+			merged[n] = n
+			if isConstant(n) {
+				key := "c" + strconv.FormatUint(math.Float64bits(float64(n.GetValue())), 16)
+				if m, ok := table[key]; ok {
+					merged[n] = m
+				} else {
+					table[key] = n
+				}
+			}
+			continue
+		}
+
+		cs := make([]Numeric[K], len(children))
+		childIDs := make([]int, len(children))
+		for i, c := range children {
+			cs[i] = merged[c]
+			childIDs[i] = id(cs[i])
+		}
+		var key string
+		if !hooked(n) {
+			key = structuralKey(n.GetOperation(), stopped(n), childIDs)
+			if m, ok := table[key]; ok {
+				merged[n] = m
+				continue
+			}
+		}
+		v := rebuild(n, n.GetOperation(), cs)
+		if key != "" {
+			table[key] = v
+		}
+		merged[n] = v
+	}
+	return merged[root]
+}
+
+// structuralKey identifies an interior node by its operation, stop-gradient
+// flag and children, in a canonical order for commutative operations.
+func structuralKey(op OperationEnum, noGrad bool, ids []int) string {
+	switch op {
+	case ADD, MUL, SUM, PROD:
+		slices.Sort(ids)
+	case DOT:
+		// sort within each (w, x) pair, then the pairs themselves
+		n := len(ids) / 2
+		pairs := make([][2]int, n)
+		for i := range pairs {
+			pairs[i] = [2]int{min(ids[i], ids[n+i]), max(ids[i], ids[n+i])}
+		}
+		slices.SortFunc(pairs, func(a, b [2]int) int {
+			if a[0] != b[0] {
+				return a[0] - b[0]
+			}
+			return a[1] - b[1]
+		})
+		for i, p := range pairs {
+			ids[2*i], ids[2*i+1] = p[0], p[1]
+		}
+	}
+
+	b := strconv.AppendInt(nil, int64(op), 10)
+	if noGrad {
+		b = append(b, '!')
+	}
+	for _, id := range ids {
+		b = append(b, ',')
+		b = strconv.AppendInt(b, int64(id), 10)
+	}
+	return string(b)
+}
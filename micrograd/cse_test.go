@@ -0,0 +1,139 @@
+package micrograd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSE(t *testing.T) {
+	t.Run("merges identical subexpressions", func(t *testing.T) {
+		x := NewValue(0.5, WithName("x"))
+		w := NewValue(-1.5, WithName("w"))
+		out := x.Mul(w).Tanh().Add(w.Mul(x).Tanh()).SetName("out")
+
+		s := CSE(out)
+
+		children := s.GetChildren()
+		assert.Same(t, children[0], children[1])
+		assert.Len(t, TopologicalOrder(s), 5)
+		assert.Len(t, TopologicalOrder(out), 7)
+		assert.Equal(t, "out", s.GetName())
+		assert.Equal(t, out.GetValue(), s.GetValue())
+	})
+
+	t.Run("gradients are unchanged", func(t *testing.T) {
+		x := NewValue(0.5, WithName("x"))
+		w := NewValue(-1.5, WithName("w"))
+		b := NewValue(0.25, WithName("b"))
+		build := func() Numeric[float64] {
+			return x.Mul(w).Add(b).Sigmoid()
+		}
+		out := Sum[float64](
+			build(), build().Mul(build()), Prod[float64](w, x, b), Prod[float64](b, x, w),
+			Dot([]Numeric[float64]{x, w}, []Numeric[float64]{w, b}),
+			Dot([]Numeric[float64]{b, w}, []Numeric[float64]{w, x}),
+			x.Pow(2), x.Pow(2), x.Sub(w), w.Sub(x), Apply[float64](hypot{}, x, w), Apply[float64](hypot{}, x, w),
+		)
+		params := []*Value[float64]{x, w, b}
+
+		out.Backward()
+		want := []float64{x.GetGradient(), w.GetGradient(), b.GetGradient()}
+		ZeroGradParams(params)
+
+		s := CSE(out)
+		s.Backward()
+
+		assert.InDelta(t, out.GetValue(), s.GetValue(), 1e-12)
+		assert.InDeltaSlice(t, want, []float64{x.GetGradient(), w.GetGradient(), b.GetGradient()}, 1e-12)
+
+		// three sigmoids, two products, two dot products and two hypots
+		// collapse to one each; subtraction is not commutative
+		count := map[OperationEnum]int{}
+		for _, n := range TopologicalOrder(s) {
+			count[n.GetOperation()]++
+		}
+		assert.Equal(t, 1, count[SIGMOID])
+		assert.Equal(t, 1, count[PROD])
+		assert.Equal(t, 1, count[DOT])
+		assert.Equal(t, 1, count[POW])
+		assert.Equal(t, 2, count[SUB])
+	})
+
+	t.Run("constants merge by value", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		two := func() *Value[float64] { return NewValue(2.0, WithRequiresGrad(false)) }
+		out := x.Mul(two()).Add(x.Mul(two())).Add(x.Mul(NewValue(5.0, WithRequiresGrad(false))))
+
+		s := CSE(out)
+
+		var constants []float64
+		for _, n := range TopologicalOrder(s) {
+			if isConstant(n) {
+				constants = append(constants, n.GetValue())
+			}
+		}
+		assert.Equal(t, []float64{2, 5}, constants)
+	})
+
+	t.Run("distinct leaves are never merged", func(t *testing.T) {
+		a := NewValue(1.0)
+		b := NewValue(1.0)
+		k := NewValue(1.0, WithName("k"), WithRequiresGrad(false))
+
+		s := CSE(Sum[float64](a.Exp(), b.Exp(), k.Exp()))
+
+		leaves := 0
+		for _, n := range TopologicalOrder(s) {
+			if len(n.GetChildren()) == 0 {
+				leaves++
+			}
+		}
+		assert.Equal(t, 3, leaves)
+	})
+
+	t.Run("stop gradients are kept apart", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		stop := x.Mul(x).(*Value[float64]).SetRequiresGrad(false)
+		out := x.Mul(x).Add(stop)
+
+		s := CSE(out)
+		s.Backward()
+
+		children := s.GetChildren()
+		assert.NotSame(t, children[0], children[1])
+		assert.Equal(t, 6.0, x.GetGradient())
+	})
+
+	t.Run("nodes with hooks are kept apart", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		hooked := x.Exp().(*Value[float64])
+		var seen []float64
+		hooked.RegisterHook(func(g float64) float64 {
+			seen = append(seen, g)
+			return g
+		})
+		out := hooked.Add(x.Exp().Mul(NewValue(2.0, WithRequiresGrad(false))))
+
+		s := CSE(out)
+		s.Backward()
+
+		assert.Equal(t, []float64{1}, seen)
+		assert.InDelta(t, 3*x.Exp().GetValue(), x.GetGradient(), 1e-12)
+	})
+
+	t.Run("original graph is untouched", func(t *testing.T) {
+		x := NewValue(0.5, WithName("x"))
+		y := x.Exp().Add(x.Exp())
+		before := TopologicalOrder(y)
+
+		s := CSE(y)
+		s.Backward()
+
+		assert.Equal(t, before, TopologicalOrder(y))
+		for _, n := range before[1:] {
+			assert.Equal(t, 0.0, n.GetGradient())
+		}
+		assert.InDelta(t, 2*x.Exp().GetValue(), x.GetGradient(), 1e-12)
+	})
+}
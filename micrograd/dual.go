@@ -0,0 +1,194 @@
+package micrograd
+
+import (
+	"fmt"
+	"math"
+)
+
+// Dual is a dual number used for forward-mode differentiation: Tangent carries
+// the derivative of Value along some chosen input direction, and every
+// operation propagates it with the chain rule as the value is computed.
+type Dual[K BaseNumeric] struct {
+	Value   K
+	Tangent K
+}
+
+func NewDual[K BaseNumeric](value, tangent K) Dual[K] {
+	return Dual[K]{Value: value, Tangent: tangent}
+}
+
+func (d Dual[K]) Add(o Dual[K]) Dual[K] {
+	return Dual[K]{d.Value + o.Value, d.Tangent + o.Tangent}
+}
+
+func (d Dual[K]) Sub(o Dual[K]) Dual[K] {
+	return Dual[K]{d.Value - o.Value, d.Tangent - o.Tangent}
+}
+
+func (d Dual[K]) Mul(o Dual[K]) Dual[K] {
+	return Dual[K]{d.Value * o.Value, d.Tangent*o.Value + d.Value*o.Tangent}
+}
+
+func (d Dual[K]) Div(o Dual[K]) Dual[K] {
+	return Dual[K]{d.Value / o.Value, (d.Tangent*o.Value - d.Value*o.Tangent) / (o.Value * o.Value)}
+}
+
+// Pow raises d to a constant exponent.
+func (d Dual[K]) Pow(exponent K) Dual[K] {
+	return d.PowDual(NewDual(exponent, 0))
+}
+
+// PowDual raises d to an exponent that carries its own tangent. As in the
+// backward pass, the exponent only contributes for a positive base.
+func (d Dual[K]) PowDual(o Dual[K]) Dual[K] {
+	base, exponent := float64(d.Value), float64(o.Value)
+	value := math.Pow(base, exponent)
+	tangent := float64(d.Tangent) * exponent * math.Pow(base, exponent-1)
+	if base > 0 {
+		tangent += float64(o.Tangent) * value * math.Log(base)
+	}
+	return Dual[K]{K(value), K(tangent)}
+}
+
+func (d Dual[K]) Neg() Dual[K] {
+	return Dual[K]{-d.Value, -d.Tangent}
+}
+
+func (d Dual[K]) Tanh() Dual[K] {
+	v := K(math.Tanh(float64(d.Value)))
+	return Dual[K]{v, d.Tangent * (1 - v*v)}
+}
+
+func (d Dual[K]) ReLU() Dual[K] {
+	if d.Value > 0 {
+		return d
+	}
+	return Dual[K]{}
+}
+
+func (d Dual[K]) Sigmoid() Dual[K] {
+	v := K(1 / (1 + math.Exp(-float64(d.Value))))
+	return Dual[K]{v, d.Tangent * v * (1 - v)}
+}
+
+func (d Dual[K]) Exp() Dual[K] {
+	v := K(math.Exp(float64(d.Value)))
+	return Dual[K]{v, d.Tangent * v}
+}
+
+func (d Dual[K]) Log() Dual[K] {
+	return Dual[K]{K(math.Log(float64(d.Value))), d.Tangent / d.Value}
+}
+
+func (d Dual[K]) Sqrt() Dual[K] {
+	v := K(math.Sqrt(float64(d.Value)))
+	return Dual[K]{v, d.Tangent / (2 * v)}
+}
+
+// DualSum adds any number of dual numbers.
+func DualSum[K BaseNumeric](values ...Dual[K]) Dual[K] {
+	var out Dual[K]
+	for _, x := range values {
+		out = out.Add(x)
+	}
+	return out
+}
+
+// DualProd multiplies any number of dual numbers.
+func DualProd[K BaseNumeric](values ...Dual[K]) Dual[K] {
+	out := NewDual[K](1, 0)
+	for _, x := range values {
+		out = out.Mul(x)
+	}
+	return out
+}
+
+// DualDot computes the inner product of ws and xs. It panics if the slices
+// differ in length.
+func DualDot[K BaseNumeric](ws, xs []Dual[K]) Dual[K] {
+	if len(ws) != len(xs) {
+		panic(fmt.Sprintf("dot product of mismatched lengths %d and %d", len(ws), len(xs)))
+	}
+	var out Dual[K]
+	for i := range ws {
+		out = out.Add(ws[i].Mul(xs[i]))
+	}
+	return out
+}
+
+// JVP evaluates the Jacobian-vector product of a built graph in a single
+// forward sweep. tangents gives the direction to differentiate along: the
+// tangent of each leaf, with leaves missing from the map treated as constant.
+// It returns the value of root together with its directional derivative.
+//
+// Frozen leaves are constant whatever their tangent, and nodes cleared with
+// SetRequiresGrad(false) pass on a zero tangent, so the result agrees with
+// the gradients computed by Backward.
+func JVP[K BaseNumeric](root Numeric[K], tangents map[Numeric[K]]K) (K, K) {
+	duals := make(map[Numeric[K]]Dual[K])
+	for _, n := range TopologicalOrder(root) {
+		children := n.GetChildren()
+		if len(children) == 0 {
+			duals[n] = NewDual(n.GetValue(), tangents[n])
+		} else {
+			inputs := make([]Dual[K], len(children))
+			for i, c := range children {
+				inputs[i] = duals[c]
+			}
+			duals[n] = applyDual(n.GetOperation(), inputs)
+		}
+		if stopped(n) {
+			duals[n] = NewDual(duals[n].Value, 0)
+		}
+	}
+	out := duals[root]
+	return out.Value, out.Tangent
+}
+
+// applyDual evaluates a single operation on dual numbers.
+func applyDual[K BaseNumeric](op OperationEnum, in []Dual[K]) Dual[K] {
+	switch op {
+	case ADD:
+		return in[0].Add(in[1])
+	case SUB:
+		return in[0].Sub(in[1])
+	case MUL:
+		return in[0].Mul(in[1])
+	case DIV:
+		return in[0].Div(in[1])
+	case POW:
+		return in[0].PowDual(in[1])
+	case NEG:
+		return in[0].Neg()
+	case TANH:
+		return in[0].Tanh()
+	case RELU:
+		return in[0].ReLU()
+	case SIGMOID:
+		return in[0].Sigmoid()
+	case EXP:
+		return in[0].Exp()
+	case LOG:
+		return in[0].Log()
+	case SQRT:
+		return in[0].Sqrt()
+	case SUM:
+		return DualSum(in...)
+	case PROD:
+		return DualProd(in...)
+	case DOT:
+		n := len(in) / 2
+		return DualDot(in[:n], in[n:])
+	default:
+		custom := mustLookupOp[K](op)
+		inputs := make([]K, len(in))
+		for i, d := range in {
+			inputs[i] = d.Value
+		}
+		out := Dual[K]{Value: custom.Forward(inputs)}
+		for i, g := range custom.Gradients(inputs, out.Value) {
+			out.Tangent += g * in[i].Tangent
+		}
+		return out
+	}
+}
@@ -0,0 +1,105 @@
+package micrograd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDual_Operations(t *testing.T) {
+	x := NewDual(0.7, 1.0)
+	y := NewDual(1.3, 0.0)
+
+	tests := []struct {
+		name    string
+		out     Dual[float64]
+		value   float64
+		tangent float64
+	}{
+		{"add", x.Add(y), 2.0, 1.0},
+		{"sub", x.Sub(y), 0.7 - 1.3, 1.0},
+		{"mul", x.Mul(y), 0.7 * 1.3, 1.3},
+		{"div", x.Div(y), 0.7 / 1.3, 1 / 1.3},
+		{"div by x", y.Div(x), 1.3 / 0.7, -1.3 / (0.7 * 0.7)},
+		{"pow", x.Pow(3), 0.7 * 0.7 * 0.7, 3 * 0.7 * 0.7},
+		{"pow dual exponent", y.PowDual(x), math.Pow(1.3, 0.7), math.Pow(1.3, 0.7) * math.Log(1.3)},
+		{"neg", x.Neg(), -0.7, -1.0},
+		{"tanh", x.Tanh(), math.Tanh(0.7), 1 - math.Tanh(0.7)*math.Tanh(0.7)},
+		{"relu", x.ReLU(), 0.7, 1.0},
+		{"relu negative", x.Neg().ReLU(), 0.0, 0.0},
+		{"sigmoid", x.Sigmoid(), 1 / (1 + math.Exp(-0.7)), math.Exp(-0.7) / math.Pow(1+math.Exp(-0.7), 2)},
+		{"exp", x.Exp(), math.Exp(0.7), math.Exp(0.7)},
+		{"log", x.Log(), math.Log(0.7), 1 / 0.7},
+		{"sqrt", x.Sqrt(), math.Sqrt(0.7), 0.5 / math.Sqrt(0.7)},
+		{"sum", DualSum(x, y, x), 2.7, 2.0},
+		{"prod", DualProd(x, y, x), 0.7 * 1.3 * 0.7, 2 * 0.7 * 1.3},
+		{"dot", DualDot([]Dual[float64]{x, y}, []Dual[float64]{y, x}), 2 * 0.7 * 1.3, 2 * 1.3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.value, tt.out.Value, 1e-12)
+			assert.InDelta(t, tt.tangent, tt.out.Tangent, 1e-12)
+		})
+	}
+}
+
+func TestJVP(t *testing.T) {
+	build := func() (Numeric[float64], []*Value[float64]) {
+		a := NewValue(0.7, WithName("a"))
+		b := NewValue(1.3, WithName("b"))
+		c := NewValue(-0.4, WithName("c"))
+		y := a.Mul(b).Sigmoid()
+		out := Sum[float64](y.Mul(y), y.Div(b), c.Tanh().Pow(2), Prod[float64](a, b, c).Exp())
+		return out, []*Value[float64]{a, b, c}
+	}
+
+	t.Run("matches the gradient along each axis", func(t *testing.T) {
+		out, leaves := build()
+		out.Backward()
+
+		for _, leaf := range leaves {
+			value, tangent := JVP(out, map[Numeric[float64]]float64{leaf: 1})
+			assert.Equal(t, out.GetValue(), value)
+			assert.InDelta(t, leaf.GetGradient(), tangent, 1e-12, leaf.GetName())
+		}
+	})
+
+	t.Run("directional derivative", func(t *testing.T) {
+		out, leaves := build()
+		out.Backward()
+
+		direction := []float64{0.5, -2, 3}
+		tangents := make(map[Numeric[float64]]float64)
+		var want float64
+		for i, leaf := range leaves {
+			tangents[leaf] = direction[i]
+			want += direction[i] * leaf.GetGradient()
+		}
+
+		_, tangent := JVP(out, tangents)
+		assert.InDelta(t, want, tangent, 1e-12)
+	})
+
+	t.Run("leaves without a tangent are constant", func(t *testing.T) {
+		out, _ := build()
+		_, tangent := JVP(out, nil)
+		assert.Equal(t, 0.0, tangent)
+	})
+
+	t.Run("stop gradients and frozen leaves", func(t *testing.T) {
+		// f = x * stop(x^2) + k * x with k frozen, df/dx = x^2 + k
+		x := NewValue(3.0)
+		k := NewValue(2.0, WithRequiresGrad(false))
+		f := x.Mul(x.Mul(x).SetRequiresGrad(false)).Add(k.Mul(x))
+		f.Backward()
+
+		_, tangent := JVP(f, map[Numeric[float64]]float64{x: 1})
+		assert.Equal(t, 11.0, tangent)
+		assert.Equal(t, x.GetGradient(), tangent)
+
+		_, tangent = JVP(f, map[Numeric[float64]]float64{k: 1})
+		assert.Equal(t, 0.0, tangent)
+	})
+}
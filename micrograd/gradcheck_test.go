@@ -0,0 +1,93 @@
+package micrograd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGradCheck_Operations(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs []float64
+		build  func(l []*Value[float64]) Numeric[float64]
+	}{
+		{"add", []float64{1.5, -2}, func(l []*Value[float64]) Numeric[float64] { return l[0].Add(l[1]) }},
+		{"sub", []float64{1.5, -2}, func(l []*Value[float64]) Numeric[float64] { return l[0].Sub(l[1]) }},
+		{"mul", []float64{1.5, -2}, func(l []*Value[float64]) Numeric[float64] { return l[0].Mul(l[1]) }},
+		{"div", []float64{1.5, -2}, func(l []*Value[float64]) Numeric[float64] { return l[0].Div(l[1]) }},
+		{"pow", []float64{1.5}, func(l []*Value[float64]) Numeric[float64] { return l[0].Pow(3) }},
+		{"pow value", []float64{1.5, 0.7}, func(l []*Value[float64]) Numeric[float64] { return l[0].PowValue(l[1]) }},
+		{"neg", []float64{1.5}, func(l []*Value[float64]) Numeric[float64] { return l[0].Neg() }},
+		{"tanh", []float64{0.3}, func(l []*Value[float64]) Numeric[float64] { return l[0].Tanh() }},
+		{"relu", []float64{0.3}, func(l []*Value[float64]) Numeric[float64] { return l[0].ReLU() }},
+		{"sigmoid", []float64{0.3}, func(l []*Value[float64]) Numeric[float64] { return l[0].Sigmoid() }},
+		{"exp", []float64{0.3}, func(l []*Value[float64]) Numeric[float64] { return l[0].Exp() }},
+		{"log", []float64{0.3}, func(l []*Value[float64]) Numeric[float64] { return l[0].Log() }},
+		{"sqrt", []float64{0.3}, func(l []*Value[float64]) Numeric[float64] { return l[0].Sqrt() }},
+		{"sum", []float64{1, 2, 3}, func(l []*Value[float64]) Numeric[float64] { return Sum[float64](l[0], l[1], l[2]) }},
+		{"prod", []float64{1, 2, 3}, func(l []*Value[float64]) Numeric[float64] { return Prod[float64](l[0], l[1], l[2]) }},
+		{"dot", []float64{1, 2, 3, 4}, func(l []*Value[float64]) Numeric[float64] {
+			return Dot([]Numeric[float64]{l[0], l[1]}, []Numeric[float64]{l[2], l[3]})
+		}},
+		{"neuron", []float64{2, 0, -3, 1, 6.88}, func(l []*Value[float64]) Numeric[float64] {
+			return Dot([]Numeric[float64]{l[0], l[1]}, []Numeric[float64]{l[2], l[3]}).Add(l[4]).Tanh()
+		}},
+		{"shared subexpression", []float64{0.7, 1.3}, func(l []*Value[float64]) Numeric[float64] {
+			y := l[0].Mul(l[1]).Sigmoid()
+			return y.Mul(y).Sub(y.Div(l[1]))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaves := make([]*Value[float64], len(tt.inputs))
+			for i, x := range tt.inputs {
+				leaves[i] = NewValue(x)
+			}
+
+			report := GradCheck(tt.build, leaves, 1e-6, 1e-6)
+			assert.True(t, report.OK(), report.String())
+			assert.Len(t, report.Results, len(leaves))
+			for i, leaf := range leaves {
+				assert.Equal(t, tt.inputs[i], leaf.GetValue(), "leaf values are restored")
+			}
+		})
+	}
+}
+
+func TestGradCheck_DetectsWrongGradients(t *testing.T) {
+	a := NewValue(2.0, WithName("a"))
+	b := NewValue(3.0, WithName("b"))
+
+	// the graph is rebuilt from a fresh leaf, so backprop never reaches a
+	build := func(l []*Value[float64]) Numeric[float64] {
+		return NewValue(l[0].GetValue() * 2).Add(l[1])
+	}
+	report := GradCheck(build, []*Value[float64]{a, b}, 1e-6, 1e-6)
+
+	assert.False(t, report.OK())
+	assert.False(t, report.Results[0].OK)
+	assert.Equal(t, "a", report.Results[0].Name)
+	assert.Equal(t, 0.0, report.Results[0].Analytic)
+	assert.InDelta(t, 2.0, report.Results[0].Numeric, 1e-6)
+	assert.True(t, report.Results[1].OK)
+	assert.InDelta(t, 1.0, report.MaxRelError, 1e-6)
+	assert.Contains(t, report.String(), "a: analytic=0")
+}
+
+func TestGradCheck_Float32(t *testing.T) {
+	leaves := []*Value[float32]{
+		NewValue[float32](2, WithName("x1")),
+		NewValue[float32](0.5, WithName("x2")),
+		NewValue[float32](-0.3, WithName("w1")),
+		NewValue[float32](0.8, WithName("w2")),
+		NewValue[float32](0.1, WithName("b")),
+	}
+	build := func(l []*Value[float32]) Numeric[float32] {
+		return Dot([]Numeric[float32]{l[0], l[1]}, []Numeric[float32]{l[2], l[3]}).Add(l[4]).Sigmoid()
+	}
+
+	report := GradCheck(build, leaves, 1e-2, 1e-3)
+	assert.True(t, report.OK(), report.String())
+}
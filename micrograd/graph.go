@@ -0,0 +1,50 @@
+package micrograd
+
+// TopologicalOrder returns every node reachable from root, children before
+// parents, with each node appearing exactly once. The walk uses an explicit
+// stack rather than recursion, so arbitrarily deep graphs cannot overflow the
+// goroutine stack, and every node and edge is visited once.
+func TopologicalOrder[K BaseNumeric](root Numeric[K]) []Numeric[K] {
+	type frame struct {
+		node Numeric[K]
+		next int
+	}
+
+	visited := map[Numeric[K]]bool{root: true}
+	stack := []frame{{node: root}}
+	var nodes []Numeric[K]
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		children := top.node.GetChildren()
+		if top.next < len(children) {
+			child := children[top.next]
+			top.next++
+			if !visited[child] {
+				visited[child] = true
+				stack = append(stack, frame{node: child})
+			}
+			continue
+		}
+
+		nodes = append(nodes, top.node)
+		stack = stack[:len(stack)-1]
+	}
+	return nodes
+}
+
+// ZeroGrad resets the gradient of every node reachable from root, leaves and
+// intermediate results alike. Backward restarts intermediate nodes on every
+// pass but adds to the leaves, so ZeroGrad is what starts a new accumulation.
+func ZeroGrad[K BaseNumeric](root Numeric[K]) {
+	for _, n := range TopologicalOrder(root) {
+		n.SetGradient(0)
+	}
+}
+
+// ZeroGradParams resets the gradients of a set of leaf parameters, typically
+// before each training step when the graph is rebuilt from scratch.
+func ZeroGradParams[K BaseNumeric](params []*Value[K]) {
+	for _, p := range params {
+		p.SetGradient(0)
+	}
+}
@@ -0,0 +1,199 @@
+package micrograd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopologicalOrder(t *testing.T) {
+	t.Run("children before parents", func(t *testing.T) {
+		a := NewValue(1.0, WithName("a"))
+		b := NewValue(2.0, WithName("b"))
+		c := a.Add(b).SetName("c")
+		d := c.Mul(a).SetName("d")
+
+		nodes := TopologicalOrder[float64](d)
+		assert.Len(t, nodes, 4)
+
+		index := make(map[Numeric[float64]]int)
+		for i, n := range nodes {
+			index[n] = i
+		}
+		assert.Less(t, index[a], index[c])
+		assert.Less(t, index[b], index[c])
+		assert.Less(t, index[c], index[d])
+	})
+
+	t.Run("shared nodes appear once", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		y := x.Mul(x).SetName("y")
+		z := y.Add(y).SetName("z")
+
+		nodes := TopologicalOrder[float64](z)
+		assert.Equal(t, []Numeric[float64]{x, y, z}, nodes)
+	})
+}
+
+func TestBackward_SharedSubexpressions(t *testing.T) {
+	t.Run("diamond", func(t *testing.T) {
+		// z = y + y, y = x * x
+		// dz/dx = 4x
+		x := NewValue(3.0, WithName("x"))
+		y := x.Mul(x).SetName("y")
+		z := y.Add(y).SetName("z")
+
+		z.Backward()
+
+		assert.Equal(t, 1.0, z.GetGradient())
+		assert.Equal(t, 2.0, y.GetGradient())
+		assert.Equal(t, 12.0, x.GetGradient())
+	})
+
+	t.Run("deeply shared", func(t *testing.T) {
+		// out = x^(2^n) built by repeated squaring of a shared node
+		// dout/dx = 2^n * x^(2^n - 1)
+		x := NewValue(1.0, WithName("x"))
+		var out Numeric[float64] = x
+		const n = 20
+		for i := 0; i < n; i++ {
+			out = out.Mul(out)
+		}
+
+		out.Backward()
+
+		assert.Equal(t, 1.0, out.GetValue())
+		assert.Equal(t, float64(1<<n), x.GetGradient())
+	})
+
+	t.Run("weight reused by several neurons", func(t *testing.T) {
+		// out = w*a + w*b + w*c
+		// dout/dw = a + b + c
+		w := NewValue(0.5, WithName("w"))
+		a := NewValue(1.0, WithName("a"))
+		b := NewValue(2.0, WithName("b"))
+		c := NewValue(3.0, WithName("c"))
+		out := w.Mul(a).Add(w.Mul(b)).Add(w.Mul(c))
+
+		out.Backward()
+
+		assert.Equal(t, 6.0, w.GetGradient())
+		assert.Equal(t, 0.5, a.GetGradient())
+		assert.Equal(t, 0.5, b.GetGradient())
+		assert.Equal(t, 0.5, c.GetGradient())
+	})
+}
+
+func TestBackward_DeepGraphs(t *testing.T) {
+	t.Run("long chain", func(t *testing.T) {
+		// out = x + 1 + 1 + ... with 100k additions
+		const n = 100_000
+		x := NewValue(0.0, WithName("x"))
+		var out Numeric[float64] = x
+		for i := 0; i < n; i++ {
+			out = out.Add(NewValue(1.0))
+		}
+
+		out.Backward()
+
+		assert.Equal(t, float64(n), out.GetValue())
+		assert.Equal(t, 1.0, x.GetGradient())
+	})
+
+	t.Run("ladder with exponentially many paths", func(t *testing.T) {
+		// each rung adds the previous rung to itself and halves the sum, so
+		// there are 2^n paths from the root down to x but each rung has
+		// derivative 2*0.5 = 1; dout/dx = 1
+		const n = 1000
+		x := NewValue(1.0, WithName("x"))
+		var out Numeric[float64] = x
+		for i := 0; i < n; i++ {
+			out = out.Add(out).Mul(NewValue(0.5))
+		}
+
+		out.Backward()
+
+		assert.Equal(t, 1.0, out.GetValue())
+		assert.Equal(t, 1.0, x.GetGradient())
+		assert.Len(t, TopologicalOrder(out), 3*n+1)
+	})
+}
+
+// chain builds a graph of n additions, each of which reuses the previous
+// node twice, so the number of nodes and edges both grow linearly in n.
+func chain(n int) Numeric[float64] {
+	var out Numeric[float64] = NewValue(1.0)
+	for i := 0; i < n; i++ {
+		out = out.Add(out).Mul(NewValue(0.5))
+	}
+	return out
+}
+
+func BenchmarkBackward(b *testing.B) {
+	for _, n := range []int{1_000, 10_000, 100_000} {
+		b.Run(fmt.Sprintf("nodes=%d", 3*n+1), func(b *testing.B) {
+			out := chain(n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				out.Backward()
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N)/float64(3*n+1), "ns/node")
+		})
+	}
+}
+
+func TestZeroGrad(t *testing.T) {
+	t.Run("resets every node", func(t *testing.T) {
+		a := NewValue(2.0, WithName("a"))
+		b := NewValue(3.0, WithName("b"))
+		c := a.Mul(b).SetName("c")
+		d := c.Add(b).SetName("d")
+
+		d.Backward()
+		ZeroGrad[float64](d)
+
+		for _, n := range TopologicalOrder[float64](d) {
+			assert.Equal(t, 0.0, n.GetGradient(), n.GetName())
+		}
+	})
+
+	t.Run("repeated backward passes", func(t *testing.T) {
+		a := NewValue(2.0, WithName("a"))
+		b := NewValue(3.0, WithName("b"))
+		c := a.Mul(b)
+		d := c.Add(b)
+
+		// each pass adds dd/da = 3 and dd/db = 3 to the leaves, nothing more
+		d.Backward()
+		d.Backward()
+		assert.Equal(t, 6.0, a.GetGradient())
+		assert.Equal(t, 6.0, b.GetGradient())
+		assert.Equal(t, 1.0, c.GetGradient())
+
+		ZeroGrad(d)
+		d.Backward()
+		assert.Equal(t, 3.0, a.GetGradient())
+		assert.Equal(t, 3.0, b.GetGradient())
+	})
+}
+
+func TestZeroGradParams(t *testing.T) {
+	// minimise (w*x - y)^2 by gradient descent, rebuilding the graph each step
+	w := NewValue(0.0, WithName("w"))
+	x := NewValue(2.0, WithName("x"))
+	y := NewValue(6.0, WithName("y"))
+	params := []*Value[float64]{w}
+
+	for step := 0; step < 50; step++ {
+		ZeroGradParams(params)
+		loss := w.Mul(x).Sub(y).Pow(2)
+		loss.Backward()
+		for _, p := range params {
+			p.SetValue(p.GetValue() - 0.05*p.GetGradient())
+		}
+	}
+
+	assert.InDelta(t, 3.0, w.GetValue(), 1e-6)
+}
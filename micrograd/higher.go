@@ -0,0 +1,156 @@
+package micrograd
+
+import "fmt"
+
+// Grad differentiates output with respect to inputs in "create graph" mode:
+// rather than accumulating plain numbers, every gradient is built as a new
+// Numeric node out of the same operations as the original graph. The results
+// can themselves be backpropagated or passed back into Grad, which gives
+// second and higher derivatives, Hessian-vector products and losses that
+// penalise gradients.
+//
+// Gradient fields of the existing nodes are left untouched. Inputs that do
+// not influence output, or do not require gradients, get a constant zero.
+func Grad[K BaseNumeric](output Numeric[K], inputs []*Value[K]) []Numeric[K] {
+	nodes := TopologicalOrder(output)
+	need := trainable(nodes)
+	contributions := map[Numeric[K]][]Numeric[K]{
+		output: {NewValue[K](1)},
+	}
+	total := make(map[Numeric[K]]Numeric[K], len(nodes))
+
+	for i := len(nodes) - 1; i >= 0; i-- {
+		n := nodes[i]
+		parts := contributions[n]
+		if len(parts) == 0 || !need[n] {
+			continue
+		}
+		g := parts[0]
+		if len(parts) > 1 {
+			g = Sum(parts...)
+		}
+		total[n] = g
+
+		children := n.GetChildren()
+		for j, local := range gradientNodes(n, g) {
+			if local != nil {
+				contributions[children[j]] = append(contributions[children[j]], local)
+			}
+		}
+	}
+
+	grads := make([]Numeric[K], len(inputs))
+	for i, in := range inputs {
+		if g, ok := total[in]; ok {
+			grads[i] = g
+		} else {
+			grads[i] = NewValue[K](0)
+		}
+	}
+	return grads
+}
+
+// HVP computes the product of the Hessian of output with the vector v, whose
+// entries correspond to inputs, by backpropagating through the gradient graph
+// built by Grad. Gradients of the nodes involved are reset first and left
+// holding the results of that second pass.
+func HVP[K BaseNumeric](output Numeric[K], inputs []*Value[K], v []K) []K {
+	if len(v) != len(inputs) {
+		panic(fmt.Sprintf("vector of length %d for %d inputs", len(v), len(inputs)))
+	}
+	grads := Grad(output, inputs)
+	vs := make([]Numeric[K], len(v))
+	for i, x := range v {
+		vs[i] = NewValue(x)
+	}
+	product := Dot(grads, vs)
+	ZeroGrad(product)
+	product.Backward()
+
+	out := make([]K, len(inputs))
+	for i, in := range inputs {
+		out[i] = in.GetGradient()
+	}
+	return out
+}
+
+// gradientNodes returns, for each child of n, the node computing dO/dchild
+// given that g computes dO/dn. A nil entry means the child receives nothing.
+func gradientNodes[K BaseNumeric](n Numeric[K], g Numeric[K]) []Numeric[K] {
+	children := n.GetChildren()
+	one := func() Numeric[K] { return NewValue[K](1) }
+
+	switch n.GetOperation() {
+	case ADD:
+		return []Numeric[K]{g, g}
+	case SUB:
+		return []Numeric[K]{g, g.Neg()}
+	case MUL:
+		a, b := children[0], children[1]
+		return []Numeric[K]{g.Mul(b), g.Mul(a)}
+	case DIV:
+		a, b := children[0], children[1]
+		return []Numeric[K]{g.Div(b), g.Mul(a).Div(b.Mul(b)).Neg()}
+	case POW:
+		a, b := children[0], children[1]
+		grads := []Numeric[K]{g.Mul(b).Mul(a.PowValue(b.Sub(one()))), nil}
+		if a.GetValue() > 0 {
+			grads[1] = g.Mul(n).Mul(a.Log())
+		}
+		return grads
+	case NEG:
+		return []Numeric[K]{g.Neg()}
+	case TANH:
+		return []Numeric[K]{g.Mul(one().Sub(n.Mul(n)))}
+	case RELU:
+		if children[0].GetValue() > 0 {
+			return []Numeric[K]{g}
+		}
+		return []Numeric[K]{nil}
+	case SIGMOID:
+		return []Numeric[K]{g.Mul(n).Mul(one().Sub(n))}
+	case EXP:
+		return []Numeric[K]{g.Mul(n)}
+	case LOG:
+		return []Numeric[K]{g.Div(children[0])}
+	case SQRT:
+		return []Numeric[K]{g.Div(n.Mul(NewValue[K](2)))}
+	case SUM:
+		grads := make([]Numeric[K], len(children))
+		for i := range grads {
+			grads[i] = g
+		}
+		return grads
+	case PROD:
+		grads := make([]Numeric[K], len(children))
+		for i := range children {
+			others := make([]Numeric[K], 0, len(children)-1)
+			others = append(others, children[:i]...)
+			others = append(others, children[i+1:]...)
+			grads[i] = g.Mul(Prod(others...))
+		}
+		return grads
+	case DOT:
+		half := len(children) / 2
+		grads := make([]Numeric[K], len(children))
+		for i := 0; i < half; i++ {
+			grads[i] = g.Mul(children[half+i])
+			grads[half+i] = g.Mul(children[i])
+		}
+		return grads
+	case UNSET:
+		return nil
+	default:
+		op, ok := mustLookupOp[K](n.GetOperation()).(GraphOp[K])
+		if !ok {
+			panic(fmt.Sprintf("operation %q does not implement GraphOp", n.GetOperation()))
+		}
+		grads := op.GradientNodes(children, n)
+		for i := range grads {
+			if grads[i] != nil {
+				grads[i] = g.Mul(grads[i])
+			}
+		}
+		return grads
+	}
+}
@@ -0,0 +1,111 @@
+package micrograd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrad(t *testing.T) {
+	t.Run("first derivatives match Backward", func(t *testing.T) {
+		a := NewValue(0.7, WithName("a"))
+		b := NewValue(1.3, WithName("b"))
+		c := NewValue(-0.4, WithName("c"))
+		y := a.Mul(b).Sigmoid()
+		out := Sum[float64](
+			y.Mul(y), y.Div(b), c.Tanh().Pow(2), Prod[float64](a, b, c).Exp(),
+			a.PowValue(b), b.Sqrt().Log(), c.Neg().ReLU(), a.Sub(c),
+			Dot([]Numeric[float64]{a, b}, []Numeric[float64]{c, c}),
+		)
+		inputs := []*Value[float64]{a, b, c}
+
+		grads := Grad(out, inputs)
+		out.Backward()
+
+		for i, in := range inputs {
+			assert.InDelta(t, in.GetGradient(), grads[i].GetValue(), 1e-12, in.GetName())
+		}
+	})
+
+	t.Run("existing gradients are untouched", func(t *testing.T) {
+		a := NewValue(2.0, WithGradient(5.0))
+		out := a.Mul(a)
+
+		Grad(out, []*Value[float64]{a})
+
+		assert.Equal(t, 5.0, a.GetGradient())
+	})
+
+	t.Run("unrelated input", func(t *testing.T) {
+		a := NewValue(2.0)
+		b := NewValue(3.0)
+
+		grads := Grad(a.Mul(a), []*Value[float64]{b})
+
+		assert.Equal(t, 0.0, grads[0].GetValue())
+	})
+
+	t.Run("frozen input", func(t *testing.T) {
+		a := NewValue(2.0)
+		b := NewValue(3.0, WithRequiresGrad(false))
+
+		grads := Grad(a.Mul(b), []*Value[float64]{a, b})
+
+		assert.Equal(t, 3.0, grads[0].GetValue())
+		assert.Equal(t, 0.0, grads[1].GetValue())
+	})
+
+	t.Run("second derivative", func(t *testing.T) {
+		// f = x^3, f' = 3x^2, f'' = 6x
+		x := NewValue(2.0, WithName("x"))
+		f := x.Pow(3)
+
+		first := Grad(f, []*Value[float64]{x})[0]
+		second := Grad(first, []*Value[float64]{x})[0]
+		third := Grad(second, []*Value[float64]{x})[0]
+
+		assert.InDelta(t, 12.0, first.GetValue(), 1e-12)
+		assert.InDelta(t, 12.0, second.GetValue(), 1e-12)
+		assert.InDelta(t, 6.0, third.GetValue(), 1e-12)
+	})
+
+	t.Run("second derivative of tanh", func(t *testing.T) {
+		// d2/dx2 tanh(x) = -2 tanh(x) (1 - tanh(x)^2)
+		x := NewValue(0.3)
+		first := Grad(x.Tanh(), []*Value[float64]{x})[0]
+		second := Grad(first, []*Value[float64]{x})[0]
+
+		th := math.Tanh(0.3)
+		assert.InDelta(t, -2*th*(1-th*th), second.GetValue(), 1e-12)
+	})
+
+	t.Run("gradient penalty", func(t *testing.T) {
+		// loss = (df/dx)^2 with f = w*x^2, so df/dx = 2wx and
+		// dloss/dw = 2 (2wx) (2x) = 8wx^2
+		w := NewValue(0.5, WithName("w"))
+		x := NewValue(3.0, WithName("x"))
+		f := w.Mul(x.Pow(2))
+
+		dfdx := Grad(f, []*Value[float64]{x})[0]
+		loss := dfdx.Pow(2)
+		loss.Backward()
+
+		assert.InDelta(t, 9.0, loss.GetValue(), 1e-12)
+		assert.InDelta(t, 36.0, w.GetGradient(), 1e-12)
+	})
+}
+
+func TestHVP(t *testing.T) {
+	// f = x^2 y + y^3
+	// H = [[2y, 2x], [2x, 6y]]
+	x := NewValue(1.5, WithName("x"))
+	y := NewValue(-0.5, WithName("y"))
+	f := x.Pow(2).Mul(y).Add(y.Pow(3))
+
+	hv := HVP(f, []*Value[float64]{x, y}, []float64{1, 2})
+
+	assert.InDelta(t, 2*-0.5*1+2*1.5*2, hv[0], 1e-12)
+	assert.InDelta(t, 2*1.5*1+6*-0.5*2, hv[1], 1e-12)
+	assert.Panics(t, func() { HVP(f, []*Value[float64]{x, y}, []float64{1}) })
+}
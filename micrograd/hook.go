@@ -0,0 +1,59 @@
+package micrograd
+
+// hook is a gradient callback registered on a Value.
+type hook[K BaseNumeric] struct {
+	fn func(grad K) K
+}
+
+// HookHandle removes a hook registered with RegisterHook.
+type HookHandle struct {
+	remove func()
+}
+
+// Remove unregisters the hook. Calling it more than once has no effect.
+func (h HookHandle) Remove() {
+	if h.remove != nil {
+		h.remove()
+	}
+}
+
+// RegisterHook adds fn to the hooks of v. During Backward and Backtrack,
+// once every parent has contributed to the gradient of v and before it is
+// passed on to the children, the hooks run in registration order, each
+// receiving the gradient and returning the one to use instead. Returning
+// grad unchanged observes the gradient without affecting it. The gradient
+// is the one that reached v in this pass; on leaves, the result is then
+// added to what earlier passes accumulated.
+//
+// Hooks only run for nodes that take part in backpropagation.
+func (v *Value[K]) RegisterHook(fn func(grad K) K) HookHandle {
+	h := &hook[K]{fn: fn}
+	v.hooks = append(v.hooks, h)
+	return HookHandle{remove: func() {
+		for i, other := range v.hooks {
+			if other == h {
+				v.hooks = append(v.hooks[:i:i], v.hooks[i+1:]...)
+				return
+			}
+		}
+	}}
+}
+
+// hooked reports whether n has hooks registered.
+func hooked[K BaseNumeric](n Numeric[K]) bool {
+	v, ok := n.(*Value[K])
+	return ok && len(v.hooks) > 0
+}
+
+// runHooks replaces the gradient of n with the result of its hooks.
+func runHooks[K BaseNumeric](n Numeric[K]) {
+	v, ok := n.(*Value[K])
+	if !ok || len(v.hooks) == 0 {
+		return
+	}
+	g := v.gradient
+	for _, h := range v.hooks {
+		g = h.fn(g)
+	}
+	v.gradient = g
+}
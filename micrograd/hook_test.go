@@ -0,0 +1,95 @@
+package micrograd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterHook(t *testing.T) {
+	t.Run("observes the final gradient", func(t *testing.T) {
+		// y is used twice, so its gradient is only final once both uses have
+		// contributed
+		x := NewValue(2.0, WithName("x"))
+		y := x.Mul(x).(*Value[float64])
+		out := y.Add(y.Mul(NewValue(3.0)))
+
+		var seen []float64
+		y.RegisterHook(func(g float64) float64 {
+			seen = append(seen, g)
+			return g
+		})
+		x.RegisterHook(func(g float64) float64 {
+			seen = append(seen, g)
+			return g
+		})
+		out.Backward()
+
+		assert.Equal(t, []float64{4, 16}, seen)
+		assert.Equal(t, 16.0, x.GetGradient())
+	})
+
+	t.Run("modifies the gradient flowing on", func(t *testing.T) {
+		x := NewValue(2.0)
+		y := x.Mul(NewValue(100.0)).(*Value[float64])
+		out := y.Tanh()
+
+		y.RegisterHook(func(g float64) float64 { return 0 })
+		out.Backward()
+
+		assert.Equal(t, 0.0, y.GetGradient())
+		assert.Equal(t, 0.0, x.GetGradient())
+	})
+
+	t.Run("hooks chain in registration order", func(t *testing.T) {
+		x := NewValue(3.0)
+		out := x.Mul(x)
+
+		x.RegisterHook(func(g float64) float64 { return g + 1 })
+		x.RegisterHook(func(g float64) float64 { return g * 10 })
+		out.Backward()
+
+		assert.Equal(t, 70.0, x.GetGradient())
+	})
+
+	t.Run("sees only the gradient of the current pass", func(t *testing.T) {
+		x := NewValue(3.0)
+		out := x.Add(NewValue(1.0))
+
+		var seen []float64
+		x.RegisterHook(func(g float64) float64 {
+			seen = append(seen, g)
+			return 2 * g
+		})
+		out.Backward()
+		assert.Equal(t, 2.0, x.GetGradient())
+		out.Backward()
+		assert.Equal(t, 4.0, x.GetGradient())
+
+		assert.Equal(t, []float64{1, 1}, seen)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		x := NewValue(3.0)
+		calls := 0
+		first := x.RegisterHook(func(g float64) float64 { calls++; return g })
+		x.RegisterHook(func(g float64) float64 { calls += 10; return g })
+
+		first.Remove()
+		first.Remove()
+		x.Mul(x).Backward()
+
+		assert.Equal(t, 10, calls)
+		HookHandle{}.Remove()
+	})
+
+	t.Run("frozen nodes are skipped", func(t *testing.T) {
+		x := NewValue(3.0, WithRequiresGrad(false))
+		called := false
+		x.RegisterHook(func(g float64) float64 { called = true; return g })
+
+		x.Mul(NewValue(2.0)).Backward()
+
+		assert.False(t, called)
+	})
+}
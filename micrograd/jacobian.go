@@ -0,0 +1,66 @@
+package micrograd
+
+import "gonum.org/v1/gonum/mat"
+
+// Jacobian returns the derivative of every output with respect to every
+// input as a dense row-major matrix: entry i*len(inputs)+j holds
+// d outputs[i] / d inputs[j]. It runs one backward pass per output, so the
+// gradients of every node involved are overwritten.
+func Jacobian[K BaseNumeric](outputs []Numeric[K], inputs []*Value[K]) []K {
+	var nodes []Numeric[K]
+	seen := make(map[Numeric[K]]bool)
+	for _, out := range outputs {
+		for _, n := range TopologicalOrder(out) {
+			if !seen[n] {
+				seen[n] = true
+				nodes = append(nodes, n)
+			}
+		}
+	}
+
+	jac := make([]K, len(outputs)*len(inputs))
+	for i, out := range outputs {
+		for _, n := range nodes {
+			n.SetGradient(0)
+		}
+		for _, in := range inputs {
+			in.SetGradient(0)
+		}
+		out.Backward()
+
+		row := jac[i*len(inputs) : (i+1)*len(inputs)]
+		for j, in := range inputs {
+			row[j] = in.GetGradient()
+		}
+	}
+	return jac
+}
+
+// Hessian returns the second derivatives of output with respect to inputs as
+// a dense row-major len(inputs) x len(inputs) matrix. It differentiates the
+// gradient graph built by Grad, so every operation involved must support
+// create graph mode.
+func Hessian[K BaseNumeric](output Numeric[K], inputs []*Value[K]) []K {
+	return Jacobian(Grad(output, inputs), inputs)
+}
+
+// JacobianDense is Jacobian returning a gonum matrix with one row per output.
+func JacobianDense[K BaseNumeric](outputs []Numeric[K], inputs []*Value[K]) *mat.Dense {
+	return dense(len(outputs), len(inputs), Jacobian(outputs, inputs))
+}
+
+// HessianDense is Hessian returning a gonum matrix.
+func HessianDense[K BaseNumeric](output Numeric[K], inputs []*Value[K]) *mat.Dense {
+	return dense(len(inputs), len(inputs), Hessian(output, inputs))
+}
+
+func dense[K BaseNumeric](rows, cols int, data []K) *mat.Dense {
+	if rows == 0 || cols == 0 {
+		return &mat.Dense{}
+	}
+	backing := make([]float64, len(data))
+	for i, x := range data {
+		backing[i] = float64(x)
+	}
+	return mat.NewDense(rows, cols, backing)
+}
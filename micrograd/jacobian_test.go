@@ -0,0 +1,89 @@
+package micrograd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestJacobian(t *testing.T) {
+	// f(x, y) = (x^2 y, 5x + e^y, x / y)
+	x := NewValue(1.5, WithName("x"))
+	y := NewValue(-0.5, WithName("y"))
+	outputs := []Numeric[float64]{
+		x.Pow(2).Mul(y),
+		x.Mul(NewValue(5.0)).Add(y.Exp()),
+		x.Div(y),
+	}
+	inputs := []*Value[float64]{x, y}
+
+	want := []float64{
+		2 * 1.5 * -0.5, 1.5 * 1.5,
+		5, math.Exp(-0.5),
+		1 / -0.5, -1.5 / (0.5 * 0.5),
+	}
+
+	t.Run("row-major", func(t *testing.T) {
+		got := Jacobian(outputs, inputs)
+		assert.InDeltaSlice(t, want, got, 1e-12)
+	})
+
+	t.Run("shared subgraphs between outputs", func(t *testing.T) {
+		shared := x.Mul(y)
+		outs := []Numeric[float64]{shared.Tanh(), shared.Mul(shared)}
+
+		got := Jacobian(outs, inputs)
+
+		slope := 1 - math.Pow(math.Tanh(-0.75), 2)
+		assert.InDeltaSlice(t, []float64{
+			slope * -0.5, slope * 1.5,
+			2 * -0.75 * -0.5, 2 * -0.75 * 1.5,
+		}, got, 1e-12)
+	})
+
+	t.Run("gonum matrix", func(t *testing.T) {
+		m := JacobianDense(outputs, inputs)
+		r, c := m.Dims()
+		assert.Equal(t, 3, r)
+		assert.Equal(t, 2, c)
+		assert.True(t, mat.EqualApprox(m, mat.NewDense(3, 2, want), 1e-12))
+	})
+
+	t.Run("float32", func(t *testing.T) {
+		a := NewValue[float32](2)
+		got := Jacobian([]Numeric[float32]{a.Mul(a), a.Neg()}, []*Value[float32]{a})
+		assert.Equal(t, []float32{4, -1}, got)
+	})
+}
+
+func TestHessian(t *testing.T) {
+	// f = x^2 y + y^3 + exp(x y)
+	x := NewValue(0.5, WithName("x"))
+	y := NewValue(-1.5, WithName("y"))
+	f := Sum[float64](x.Pow(2).Mul(y), y.Pow(3), x.Mul(y).Exp())
+	inputs := []*Value[float64]{x, y}
+
+	e := math.Exp(0.5 * -1.5)
+	want := []float64{
+		2*-1.5 + -1.5*-1.5*e, 2*0.5 + e + 0.5*-1.5*e,
+		2*0.5 + e + 0.5*-1.5*e, 6*-1.5 + 0.5*0.5*e,
+	}
+
+	t.Run("row-major", func(t *testing.T) {
+		assert.InDeltaSlice(t, want, Hessian(f, inputs), 1e-12)
+	})
+
+	t.Run("gonum matrix", func(t *testing.T) {
+		m := HessianDense(f, inputs)
+		assert.True(t, mat.EqualApprox(m, mat.NewDense(2, 2, want), 1e-12))
+		assert.True(t, mat.EqualApprox(m, m.T(), 1e-12), "hessian is symmetric")
+	})
+
+	t.Run("input absent from the graph", func(t *testing.T) {
+		z := NewValue(3.0)
+		h := Hessian(x.Pow(3), []*Value[float64]{x, z})
+		assert.InDeltaSlice(t, []float64{6 * 0.5, 0, 0, 0}, h, 1e-12)
+	})
+}
@@ -0,0 +1,218 @@
+package micrograd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// graphFormatVersion is bumped whenever the JSON layout changes
+// incompatibly.
+const graphFormatVersion = 1
+
+// opNames are the stable names built-in operations are serialized under.
+// Registered operations are serialized under their Op name.
+var opNames = map[OperationEnum]string{
+	ADD:     "add",
+	SUB:     "sub",
+	MUL:     "mul",
+	DIV:     "div",
+	POW:     "pow",
+	NEG:     "neg",
+	TANH:    "tanh",
+	RELU:    "relu",
+	SIGMOID: "sigmoid",
+	EXP:     "exp",
+	LOG:     "log",
+	SQRT:    "sqrt",
+	SUM:     "sum",
+	PROD:    "prod",
+	DOT:     "dot",
+}
+
+type graphJSON struct {
+	Version int        `json:"version"`
+	Root    int        `json:"root"`
+	Nodes   []nodeJSON `json:"nodes"`
+}
+
+// jsonFloat is a float64 that also round-trips NaN and ±Inf, which JSON
+// numbers cannot represent, as the strings "NaN", "Inf" and "-Inf".
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	x := float64(f)
+	switch {
+	case math.IsNaN(x):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(x, 1):
+		return []byte(`"Inf"`), nil
+	case math.IsInf(x, -1):
+		return []byte(`"-Inf"`), nil
+	}
+	return json.Marshal(x)
+}
+
+func (f *jsonFloat) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case `"NaN"`:
+		*f = jsonFloat(math.NaN())
+	case `"Inf"`:
+		*f = jsonFloat(math.Inf(1))
+	case `"-Inf"`:
+		*f = jsonFloat(math.Inf(-1))
+	default:
+		var x float64
+		if err := json.Unmarshal(data, &x); err != nil {
+			return err
+		}
+		*f = jsonFloat(x)
+	}
+	return nil
+}
+
+type nodeJSON struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name,omitempty"`
+	Op       string    `json:"op,omitempty"`
+	Value    jsonFloat `json:"value"`
+	Grad     jsonFloat `json:"grad"`
+	NoGrad   bool      `json:"no_grad,omitempty"`
+	Children []int     `json:"children,omitempty"`
+}
+
+// MarshalGraph encodes the graph rooted at root as indented JSON. Nodes are
+// listed once each in topological order, with IDs matching their position,
+// and refer to their children by ID, so shared nodes stay shared.
+func MarshalGraph[K BaseNumeric](root Numeric[K]) ([]byte, error) {
+	nodes := TopologicalOrder(root)
+	ids := make(map[Numeric[K]]int, len(nodes))
+	out := graphJSON{Version: graphFormatVersion, Nodes: make([]nodeJSON, len(nodes))}
+
+	for i, n := range nodes {
+		ids[n] = i
+		node := nodeJSON{
+			ID:     i,
+			Name:   n.GetName(),
+			Value:  jsonFloat(n.GetValue()),
+			Grad:   jsonFloat(n.GetGradient()),
+			NoGrad: stopped(n),
+		}
+		if children := n.GetChildren(); len(children) > 0 {
+			name, ok := operationName(n.GetOperation())
+			if !ok {
+				return nil, fmt.Errorf("node %d: unknown operation %q", i, n.GetOperation())
+			}
+			node.Op = name
+			node.Children = make([]int, len(children))
+			for j, c := range children {
+				node.Children[j] = ids[c]
+			}
+		}
+		out.Nodes[i] = node
+	}
+	out.Root = ids[root]
+
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// UnmarshalGraph rebuilds a graph encoded by MarshalGraph and returns its
+// root. Values and gradients are restored as stored, without re-evaluating
+// the graph. Custom operations must be registered before loading.
+func UnmarshalGraph[K BaseNumeric](data []byte) (*Value[K], error) {
+	var in graphJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+	if in.Version != graphFormatVersion {
+		return nil, fmt.Errorf("unsupported graph format version %d", in.Version)
+	}
+	if in.Root < 0 || in.Root >= len(in.Nodes) {
+		return nil, fmt.Errorf("root %d out of range", in.Root)
+	}
+
+	nodes := make([]*Value[K], len(in.Nodes))
+	for i, node := range in.Nodes {
+		if node.ID != i {
+			return nil, fmt.Errorf("node %d: out of order id %d", i, node.ID)
+		}
+		v := &Value[K]{
+			Name:     node.Name,
+			datum:    K(node.Value),
+			gradient: K(node.Grad),
+			noGrad:   node.NoGrad,
+		}
+
+		if node.Op != "" {
+			op, ok := operationByName[K](node.Op)
+			if !ok {
+				return nil, fmt.Errorf("node %d: unknown operation %q", i, node.Op)
+			}
+			if err := checkArity[K](op, len(node.Children)); err != nil {
+				return nil, fmt.Errorf("node %d: %v", i, err)
+			}
+			v.operation = op
+			v.children = make([]Numeric[K], len(node.Children))
+			for j, c := range node.Children {
+				// children always precede their parents, which also rules out cycles
+				if c < 0 || c >= i {
+					return nil, fmt.Errorf("node %d: invalid child reference %d", i, c)
+				}
+				v.children[j] = nodes[c]
+			}
+		} else if len(node.Children) > 0 {
+			return nil, fmt.Errorf("node %d: children without an operation", i)
+		}
+		nodes[i] = v
+	}
+
+	return nodes[in.Root], nil
+}
+
+func operationName(op OperationEnum) (string, bool) {
+	if name, ok := opNames[op]; ok {
+		return name, true
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	entry, ok := registry.ops[op]
+	return entry.name, ok
+}
+
+func operationByName[K BaseNumeric](name string) (OperationEnum, bool) {
+	for op, n := range opNames {
+		if n == name {
+			return op, true
+		}
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	op, ok := registry.byName[keyOf[K](name)]
+	return op, ok
+}
+
+// checkArity validates the number of children of an operation.
+func checkArity[K BaseNumeric](op OperationEnum, n int) error {
+	want := -1
+	switch op {
+	case ADD, SUB, MUL, DIV, POW:
+		want = 2
+	case NEG, TANH, RELU, SIGMOID, EXP, LOG, SQRT:
+		want = 1
+	case SUM, PROD:
+	case DOT:
+		if n%2 != 0 {
+			return fmt.Errorf("dot product needs an even number of children, got %d", n)
+		}
+	default:
+		custom, ok := LookupOp[K](op)
+		if !ok {
+			return fmt.Errorf("operation %q is not registered for this float type", op)
+		}
+		want = custom.Arity()
+	}
+	if want >= 0 && n != want {
+		return fmt.Errorf("operation %q takes %d children, got %d", op, want, n)
+	}
+	return nil
+}
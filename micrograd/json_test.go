@@ -0,0 +1,189 @@
+package micrograd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalGraph(t *testing.T) {
+	t.Run("format", func(t *testing.T) {
+		a := NewValue(2.0, WithName("a"))
+		b := NewValue(-3.0, WithName("b"))
+		c := a.Mul(b).SetName("c")
+		c.Backward()
+
+		data, err := MarshalGraph[float64](c)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"version": 1,
+			"root": 2,
+			"nodes": [
+				{"id": 0, "name": "a", "value": 2, "grad": -3},
+				{"id": 1, "name": "b", "value": -3, "grad": 2},
+				{"id": 2, "name": "c", "op": "mul", "value": -6, "grad": 1, "children": [0, 1]}
+			]
+		}`, string(data))
+	})
+
+	t.Run("frozen nodes", func(t *testing.T) {
+		a := NewValue(2.0, WithName("a"))
+		k := NewValue(0.5, WithName("k"), WithRequiresGrad(false))
+		c := a.Mul(k).SetName("c")
+
+		data, err := MarshalGraph[float64](c)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"version": 1,
+			"root": 2,
+			"nodes": [
+				{"id": 0, "name": "a", "value": 2, "grad": 0},
+				{"id": 1, "name": "k", "value": 0.5, "grad": 0, "no_grad": true},
+				{"id": 2, "name": "c", "op": "mul", "value": 1, "grad": 0, "children": [0, 1]}
+			]
+		}`, string(data))
+
+		loaded, err := UnmarshalGraph[float64](data)
+		assert.NoError(t, err)
+		loaded.Backward()
+		children := loaded.GetChildren()
+		assert.Equal(t, 0.5, children[0].GetGradient())
+		assert.False(t, children[1].RequiresGrad())
+		assert.Equal(t, 0.0, children[1].GetGradient())
+	})
+
+	t.Run("shared nodes are written once", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		y := x.Mul(x).SetName("y")
+		z := y.Add(y).SetName("z")
+
+		data, err := MarshalGraph[float64](z)
+		assert.NoError(t, err)
+
+		loaded, err := UnmarshalGraph[float64](data)
+		assert.NoError(t, err)
+		assert.Len(t, TopologicalOrder[float64](loaded), 3)
+
+		children := loaded.GetChildren()
+		assert.Same(t, children[0], children[1])
+		grandchildren := children[0].GetChildren()
+		assert.Same(t, grandchildren[0], grandchildren[1])
+	})
+}
+
+func TestUnmarshalGraph(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		a := NewValue(0.7, WithName("a"))
+		b := NewValue(1.3, WithName("b"))
+		c := NewValue(-0.4, WithName("c"))
+		y := a.Mul(b).Sigmoid().SetName("y")
+		out := Sum[float64](
+			y.Mul(y), y.Div(b), c.Tanh().Pow(2), Prod[float64](a, b, c).Exp(),
+			a.PowValue(b), b.Sqrt().Log(), c.Neg().ReLU(), a.Sub(c), a.Add(c),
+			Dot([]Numeric[float64]{a, b}, []Numeric[float64]{c, c}),
+			Apply[float64](hypot{}, a, b),
+		).SetName("out")
+		out.Backward()
+
+		data, err := MarshalGraph[float64](out)
+		assert.NoError(t, err)
+
+		loaded, err := UnmarshalGraph[float64](data)
+		assert.NoError(t, err)
+		assert.Equal(t, "out", loaded.GetName())
+		assert.Equal(t, out.GetValue(), loaded.GetValue())
+
+		again, err := MarshalGraph[float64](loaded)
+		assert.NoError(t, err)
+		assert.Equal(t, string(data), string(again))
+
+		// the loaded graph is fully functional
+		original := TopologicalOrder[float64](out)
+		ZeroGrad[float64](loaded)
+		loaded.Backward()
+		for i, n := range TopologicalOrder[float64](loaded) {
+			assert.InDelta(t, original[i].GetGradient(), n.GetGradient(), 1e-12)
+		}
+		assert.InDelta(t, out.GetValue(), loaded.Forward(), 1e-12)
+	})
+
+	t.Run("non-finite values", func(t *testing.T) {
+		x := NewValue(0.0, WithName("x"))
+		n := NewValue(math.NaN(), WithName("n"))
+		out := x.Log().Add(x.Sqrt()).Add(n).SetName("out")
+		out.Backward()
+
+		data, err := MarshalGraph[float64](out)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"value": "-Inf"`)
+		assert.Contains(t, string(data), `"grad": "Inf"`)
+		assert.Contains(t, string(data), `"value": "NaN"`)
+
+		loaded, err := UnmarshalGraph[float64](data)
+		assert.NoError(t, err)
+		assert.True(t, math.IsNaN(loaded.GetValue()))
+		for i, n := range TopologicalOrder[float64](loaded) {
+			original := TopologicalOrder[float64](out)[i]
+			assert.Equal(t, math.IsNaN(original.GetValue()), math.IsNaN(n.GetValue()))
+			if !math.IsNaN(original.GetValue()) {
+				assert.Equal(t, original.GetValue(), n.GetValue())
+			}
+			assert.Equal(t, original.GetGradient(), n.GetGradient())
+		}
+
+		again, err := MarshalGraph[float64](loaded)
+		assert.NoError(t, err)
+		assert.Equal(t, string(data), string(again))
+
+		_, err = UnmarshalGraph[float64]([]byte(`{"version": 1, "nodes": [{"id": 0, "value": "inf", "grad": 0}]}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("float32", func(t *testing.T) {
+		a := NewValue[float32](1.5, WithName("a"))
+		out := a.Mul(a).SetName("out")
+
+		data, err := MarshalGraph[float32](out)
+		assert.NoError(t, err)
+		loaded, err := UnmarshalGraph[float32](data)
+		assert.NoError(t, err)
+		assert.Equal(t, float32(2.25), loaded.GetValue())
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		tests := []struct {
+			name string
+			data string
+		}{
+			{"malformed", `{`},
+			{"version", `{"version": 2, "root": 0, "nodes": [{"id": 0, "value": 1, "grad": 0}]}`},
+			{"root out of range", `{"version": 1, "root": 1, "nodes": [{"id": 0, "value": 1, "grad": 0}]}`},
+			{"id out of order", `{"version": 1, "root": 0, "nodes": [{"id": 1, "value": 1, "grad": 0}]}`},
+			{"unknown operation", `{"version": 1, "root": 1, "nodes": [
+				{"id": 0, "value": 1, "grad": 0},
+				{"id": 1, "op": "frobnicate", "children": [0], "value": 1, "grad": 0}]}`},
+			{"forward reference", `{"version": 1, "root": 0, "nodes": [
+				{"id": 0, "op": "neg", "children": [1], "value": 1, "grad": 0},
+				{"id": 1, "value": 1, "grad": 0}]}`},
+			{"self reference", `{"version": 1, "root": 0, "nodes": [
+				{"id": 0, "op": "neg", "children": [0], "value": 1, "grad": 0}]}`},
+			{"wrong arity", `{"version": 1, "root": 1, "nodes": [
+				{"id": 0, "value": 1, "grad": 0},
+				{"id": 1, "op": "add", "children": [0], "value": 1, "grad": 0}]}`},
+			{"odd dot product", `{"version": 1, "root": 1, "nodes": [
+				{"id": 0, "value": 1, "grad": 0},
+				{"id": 1, "op": "dot", "children": [0, 0, 0], "value": 1, "grad": 0}]}`},
+			{"children without operation", `{"version": 1, "root": 1, "nodes": [
+				{"id": 0, "value": 1, "grad": 0},
+				{"id": 1, "children": [0], "value": 1, "grad": 0}]}`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := UnmarshalGraph[float64]([]byte(tt.data))
+				assert.Error(t, err)
+			})
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package micrograd
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Op is a differentiable operation defined outside this package. Once
+// registered with RegisterOp it can be applied to values with Apply, and
+// nodes built from it take part in backpropagation, forward mode and
+// plotting just like the built-in operations.
+type Op[K BaseNumeric] interface {
+	// Name uniquely identifies the operation in the registry.
+	Name() string
+	// Symbol is the label used when displaying the operation.
+	Symbol() string
+	// Arity is the number of inputs the operation takes, or a negative
+	// number if it accepts any number of inputs.
+	Arity() int
+	// Forward computes the output from the input values.
+	Forward(inputs []K) K
+	// Gradients returns the partial derivative of the output with respect
+	// to each input, given the inputs and the output Forward produced.
+	Gradients(inputs []K, output K) []K
+}
+
+// GraphOp is implemented by operations that can express their partial
+// derivatives as graph nodes, which Grad needs for higher-order derivatives.
+type GraphOp[K BaseNumeric] interface {
+	Op[K]
+	// GradientNodes returns a node computing the partial derivative of
+	// output with respect to each input.
+	GradientNodes(inputs []Numeric[K], output Numeric[K]) []Numeric[K]
+}
+
+// firstCustomOp lies past the last Unicode code point, so registered
+// operations never collide with the rune-valued built-in operations.
+const firstCustomOp = 0x110000
+
+// opKey identifies a registration: the same name may be registered once for
+// each float type.
+type opKey struct {
+	name string
+	typ  reflect.Type
+}
+
+func keyOf[K BaseNumeric](name string) opKey {
+	return opKey{name: name, typ: reflect.TypeFor[K]()}
+}
+
+type registeredOp struct {
+	name   string
+	symbol string
+	op     any
+}
+
+var registry = struct {
+	sync.RWMutex
+	byName map[opKey]OperationEnum
+	ops    map[OperationEnum]registeredOp
+	next   OperationEnum
+}{
+	byName: make(map[opKey]OperationEnum),
+	ops:    make(map[OperationEnum]registeredOp),
+	next:   firstCustomOp,
+}
+
+// RegisterOp adds op to the registry and returns the OperationEnum that nodes
+// built from it carry. Names must be unique per float type: the float32 and
+// float64 versions of an operation may share a name, and get distinct ids.
+func RegisterOp[K BaseNumeric](op Op[K]) (OperationEnum, error) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	key := keyOf[K](op.Name())
+	if _, ok := registry.byName[key]; ok {
+		return UNSET, fmt.Errorf("operation %q is already registered for %v", op.Name(), key.typ)
+	}
+	id := registry.next
+	registry.next++
+	registry.byName[key] = id
+	registry.ops[id] = registeredOp{name: op.Name(), symbol: op.Symbol(), op: op}
+	return id, nil
+}
+
+// MustRegisterOp is like RegisterOp but panics on error. It simplifies
+// registering operations in package-level variables.
+func MustRegisterOp[K BaseNumeric](op Op[K]) OperationEnum {
+	id, err := RegisterOp(op)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// LookupOp returns the registered operation behind id.
+func LookupOp[K BaseNumeric](id OperationEnum) (Op[K], bool) {
+	registry.RLock()
+	entry, ok := registry.ops[id]
+	registry.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	op, ok := entry.op.(Op[K])
+	return op, ok
+}
+
+// Apply builds a node computing op on inputs. It panics if op has not been
+// registered for K or is given the wrong number of inputs.
+func Apply[K BaseNumeric](op Op[K], inputs ...Numeric[K]) Numeric[K] {
+	return newNode(checkApply(op, len(inputs)), inputs...)
+}
+
+// checkApply returns the id op was registered under, panicking if it was
+// not registered for K or does not take n inputs.
+func checkApply[K BaseNumeric](op Op[K], n int) OperationEnum {
+	key := keyOf[K](op.Name())
+	registry.RLock()
+	id, ok := registry.byName[key]
+	registry.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("operation %q is not registered for %v", op.Name(), key.typ))
+	}
+	if op.Arity() >= 0 && op.Arity() != n {
+		panic(fmt.Sprintf("operation %q takes %d inputs, got %d", op.Name(), op.Arity(), n))
+	}
+	return id
+}
+
+// customSymbol returns the display symbol of a registered operation.
+func customSymbol(id OperationEnum) (string, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	entry, ok := registry.ops[id]
+	return entry.symbol, ok
+}
+
+// mustLookupOp is used by the engine when it meets an operation it does not
+// know natively.
+func mustLookupOp[K BaseNumeric](id OperationEnum) Op[K] {
+	op, ok := LookupOp[K](id)
+	if !ok {
+		panic(fmt.Sprintf("unknown operation %q", id))
+	}
+	return op
+}
+
+func values[K BaseNumeric](nodes []Numeric[K]) []K {
+	out := make([]K, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.GetValue()
+	}
+	return out
+}
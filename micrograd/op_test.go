@@ -0,0 +1,239 @@
+package micrograd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// square is a unary operation that also supports create graph mode.
+type square struct{}
+
+func (square) Name() string   { return "test.square" }
+func (square) Symbol() string { return "²" }
+func (square) Arity() int     { return 1 }
+
+func (square) Forward(inputs []float64) float64 {
+	return inputs[0] * inputs[0]
+}
+
+func (square) Gradients(inputs []float64, _ float64) []float64 {
+	return []float64{2 * inputs[0]}
+}
+
+func (square) GradientNodes(inputs []Numeric[float64], _ Numeric[float64]) []Numeric[float64] {
+	return []Numeric[float64]{inputs[0].Mul(NewValue(2.0))}
+}
+
+// hypot is a binary operation with only numeric gradients.
+type hypot struct{}
+
+func (hypot) Name() string   { return "test.hypot" }
+func (hypot) Symbol() string { return "hypot" }
+func (hypot) Arity() int     { return 2 }
+
+func (hypot) Forward(inputs []float64) float64 {
+	return math.Hypot(inputs[0], inputs[1])
+}
+
+func (hypot) Gradients(inputs []float64, output float64) []float64 {
+	return []float64{inputs[0] / output, inputs[1] / output}
+}
+
+// logSumExp accepts any number of inputs.
+type logSumExp struct{}
+
+func (logSumExp) Name() string   { return "test.logsumexp" }
+func (logSumExp) Symbol() string { return "lse" }
+func (logSumExp) Arity() int     { return -1 }
+
+func (logSumExp) Forward(inputs []float64) float64 {
+	var sum float64
+	for _, x := range inputs {
+		sum += math.Exp(x)
+	}
+	return math.Log(sum)
+}
+
+func (logSumExp) Gradients(inputs []float64, output float64) []float64 {
+	grads := make([]float64, len(inputs))
+	for i, x := range inputs {
+		grads[i] = math.Exp(x - output)
+	}
+	return grads
+}
+
+var (
+	squareOp    = MustRegisterOp[float64](square{})
+	hypotOp     = MustRegisterOp[float64](hypot{})
+	logSumExpOp = MustRegisterOp[float64](logSumExp{})
+)
+
+func TestRegisterOp(t *testing.T) {
+	t.Run("duplicate names", func(t *testing.T) {
+		_, err := RegisterOp[float64](square{})
+		assert.Error(t, err)
+		assert.Panics(t, func() { MustRegisterOp[float64](square{}) })
+	})
+
+	t.Run("same name for another float type", func(t *testing.T) {
+		id, err := RegisterOp[float32](hypotFloat32{})
+		assert.NoError(t, err)
+		assert.NotEqual(t, hypotOp, id)
+
+		op, ok := LookupOp[float32](id)
+		assert.True(t, ok)
+		assert.Equal(t, "test.hypot", op.Name())
+
+		out := Apply[float32](hypotFloat32{}, NewValue[float32](3), NewValue[float32](4))
+		assert.Equal(t, id, out.GetOperation())
+		assert.Equal(t, float32(5), out.GetValue())
+		assert.Equal(t, hypotOp, Apply[float64](hypot{}, NewValue(3.0), NewValue(4.0)).GetOperation())
+
+		_, err = RegisterOp[float32](hypotFloat32{})
+		assert.EqualError(t, err, `operation "test.hypot" is already registered for float32`)
+	})
+
+	t.Run("distinct ids", func(t *testing.T) {
+		assert.NotEqual(t, squareOp, hypotOp)
+		assert.GreaterOrEqual(t, int(squareOp), firstCustomOp)
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		op, ok := LookupOp[float64](hypotOp)
+		assert.True(t, ok)
+		assert.Equal(t, "test.hypot", op.Name())
+
+		_, ok = LookupOp[float32](hypotOp)
+		assert.False(t, ok, "registered for float64 only")
+
+		_, ok = LookupOp[float64](ADD)
+		assert.False(t, ok)
+	})
+
+	t.Run("symbols", func(t *testing.T) {
+		assert.Equal(t, "²", squareOp.String())
+		assert.Equal(t, "hypot", hypotOp.String())
+	})
+}
+
+func TestApply(t *testing.T) {
+	t.Run("builds a node", func(t *testing.T) {
+		a := NewValue(3.0)
+		b := NewValue(4.0)
+		out := Apply[float64](hypot{}, a, b)
+
+		assert.Equal(t, 5.0, out.GetValue())
+		assert.Equal(t, OperationEnum(hypotOp), out.GetOperation())
+		assert.Equal(t, []Numeric[float64]{a, b}, out.GetChildren())
+	})
+
+	t.Run("forward", func(t *testing.T) {
+		a := NewValue(3.0)
+		b := NewValue(4.0)
+		out := Apply[float64](hypot{}, a, b)
+
+		a.SetValue(6.0)
+		b.SetValue(8.0)
+		assert.Equal(t, 10.0, out.Forward())
+	})
+
+	t.Run("wrong arity", func(t *testing.T) {
+		assert.Panics(t, func() { Apply[float64](hypot{}, NewValue(1.0)) })
+	})
+
+	t.Run("unregistered", func(t *testing.T) {
+		assert.PanicsWithValue(t, `operation "test.hypot32" is not registered for float32`, func() {
+			Apply[float32](hypot32{}, NewValue[float32](1), NewValue[float32](1))
+		})
+	})
+
+	t.Run("registered for another float type only", func(t *testing.T) {
+		assert.PanicsWithValue(t, `operation "test.square" is not registered for float32`, func() {
+			Apply[float32](square32{}, NewValue[float32](1))
+		})
+	})
+
+	t.Run("backward", func(t *testing.T) {
+		// out = hypot(a, b)^2 = a^2 + b^2
+		a := NewValue(3.0)
+		b := NewValue(4.0)
+		out := Apply[float64](square{}, Apply[float64](hypot{}, a, b))
+
+		out.Backward()
+
+		assert.InDelta(t, 25.0, out.GetValue(), 1e-12)
+		assert.InDelta(t, 6.0, a.GetGradient(), 1e-12)
+		assert.InDelta(t, 8.0, b.GetGradient(), 1e-12)
+	})
+
+	t.Run("gradient check", func(t *testing.T) {
+		leaves := []*Value[float64]{NewValue(0.3), NewValue(-1.2), NewValue(2.0)}
+		build := func(l []*Value[float64]) Numeric[float64] {
+			h := Apply[float64](hypot{}, l[0], l[1])
+			return Apply[float64](logSumExp{}, h, Apply[float64](square{}, l[2]), l[0]).Tanh()
+		}
+
+		report := GradCheck(build, leaves, 1e-6, 1e-6)
+		assert.True(t, report.OK(), report.String())
+	})
+
+	t.Run("forward mode", func(t *testing.T) {
+		a := NewValue(3.0)
+		b := NewValue(4.0)
+		out := Apply[float64](logSumExp{}, Apply[float64](hypot{}, a, b), a)
+		out.Backward()
+
+		_, tangent := JVP(out, map[Numeric[float64]]float64{a: 1})
+		assert.InDelta(t, a.GetGradient(), tangent, 1e-12)
+	})
+
+	t.Run("create graph mode", func(t *testing.T) {
+		// d2/dx2 square(x)^2 = d2/dx2 x^4 = 12x^2
+		x := NewValue(2.0)
+		out := Apply[float64](square{}, x).Pow(2)
+
+		first := Grad(out, []*Value[float64]{x})[0]
+		second := Grad(first, []*Value[float64]{x})[0]
+		assert.InDelta(t, 32.0, first.GetValue(), 1e-12)
+		assert.InDelta(t, 48.0, second.GetValue(), 1e-12)
+
+		assert.Panics(t, func() {
+			Grad(Apply[float64](hypot{}, x, x), []*Value[float64]{x})
+		}, "hypot does not implement GraphOp")
+	})
+}
+
+// hypotFloat32 is the float32 version of hypot, registered by TestRegisterOp.
+type hypotFloat32 struct{}
+
+func (hypotFloat32) Name() string   { return "test.hypot" }
+func (hypotFloat32) Symbol() string { return "hypot" }
+func (hypotFloat32) Arity() int     { return 2 }
+
+func (hypotFloat32) Forward(inputs []float32) float32 {
+	return float32(math.Hypot(float64(inputs[0]), float64(inputs[1])))
+}
+
+func (hypotFloat32) Gradients(inputs []float32, output float32) []float32 {
+	return []float32{inputs[0] / output, inputs[1] / output}
+}
+
+// square32 shares its name with square but is never registered.
+type square32 struct{}
+
+func (square32) Name() string                           { return "test.square" }
+func (square32) Symbol() string                         { return "²" }
+func (square32) Arity() int                             { return 1 }
+func (square32) Forward([]float32) float32              { return 0 }
+func (square32) Gradients([]float32, float32) []float32 { return nil }
+
+// hypot32 is never registered.
+type hypot32 struct{}
+
+func (hypot32) Name() string                           { return "test.hypot32" }
+func (hypot32) Symbol() string                         { return "hypot" }
+func (hypot32) Arity() int                             { return 2 }
+func (hypot32) Forward([]float32) float32              { return 0 }
+func (hypot32) Gradients([]float32, float32) []float32 { return nil }
@@ -0,0 +1,121 @@
+package micrograd
+
+import (
+	"runtime"
+	"sync"
+)
+
+// minChunk is the smallest number of nodes handed to a worker at once; below
+// it, scheduling costs more than the chain rule itself.
+const minChunk = 32
+
+// BackwardParallel is the concurrent counterpart of Backward: it seeds the
+// gradient of root with 1 and backpropagates it using up to workers
+// goroutines, or GOMAXPROCS when workers is not positive.
+//
+// Nodes are grouped into levels by their longest distance from root, so a
+// level never holds a node together with one of its ancestors, and the nodes
+// of a level are processed concurrently. Rather than adding into its
+// children, each node writes its local contributions to a buffer of its own
+// and gathers its gradient from the buffers of its parents, so every field is
+// written by a single goroutine and no locking is needed. Results match
+// Backward, including which gradients accumulate across passes, up to the
+// order in which contributions are summed.
+//
+// Hooks and the Gradients method of registered operations may be called from
+// several goroutines at once.
+func BackwardParallel[K BaseNumeric](root Numeric[K], workers int) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	root.SetGradient(1)
+
+	type edge struct {
+		parent, slot int
+	}
+
+	nodes := TopologicalOrder(root)
+	need := trainable(nodes)
+	index := make(map[Numeric[K]]int, len(nodes))
+	for i, n := range nodes {
+		index[n] = i
+	}
+
+	// Parents come after their children in topological order, so walking it
+	// backwards settles the level of a node before it is used.
+	parents := make([][]edge, len(nodes))
+	level := make([]int, len(nodes))
+	var levels [][]int
+	for i := len(nodes) - 1; i >= 0; i-- {
+		if need[nodes[i]] {
+			for len(levels) <= level[i] {
+				levels = append(levels, nil)
+			}
+			levels[level[i]] = append(levels[level[i]], i)
+		}
+		for slot, c := range nodes[i].GetChildren() {
+			j := index[c]
+			parents[j] = append(parents[j], edge{parent: i, slot: slot})
+			level[j] = max(level[j], level[i]+1)
+		}
+	}
+
+	contributions := make([][]K, len(nodes))
+	process := func(i int) {
+		n := nodes[i]
+		children := n.GetChildren()
+		prior := n.GetGradient()
+		if len(parents[i]) > 0 {
+			var g K
+			for _, e := range parents[i] {
+				if buf := contributions[e.parent]; buf != nil {
+					g += buf[e.slot]
+				}
+			}
+			n.SetGradient(g)
+		}
+		runHooks(n)
+
+		if len(children) == 0 {
+			if len(parents[i]) > 0 {
+				n.SetGradient(prior + n.GetGradient())
+			}
+			return
+		}
+		buf := make([]K, len(children))
+		propagate(n, func(j int, g K) {
+			buf[j] += g
+		})
+		contributions[i] = buf
+	}
+
+	var wg sync.WaitGroup
+	jobs := make(chan []int)
+	defer close(jobs)
+	for w := 0; w < workers; w++ {
+		go func() {
+			for chunk := range jobs {
+				for _, i := range chunk {
+					process(i)
+				}
+				wg.Done()
+			}
+		}()
+	}
+
+	for _, lvl := range levels {
+		chunks := min(workers, (len(lvl)+minChunk-1)/minChunk)
+		if chunks <= 1 {
+			for _, i := range lvl {
+				process(i)
+			}
+			continue
+		}
+		size := (len(lvl) + chunks - 1) / chunks
+		for start := 0; start < len(lvl); start += size {
+			wg.Add(1)
+			jobs <- lvl[start:min(start+size, len(lvl))]
+		}
+		wg.Wait()
+	}
+}
@@ -0,0 +1,123 @@
+package micrograd
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertSameGradients backpropagates root serially and with
+// BackwardParallel, and checks every node ends up with the same gradient.
+func assertSameGradients(t *testing.T, root Numeric[float64], workers int) {
+	t.Helper()
+	nodes := TopologicalOrder(root)
+
+	ZeroGrad(root)
+	root.Backward()
+	want := make([]float64, len(nodes))
+	for i, n := range nodes {
+		want[i] = n.GetGradient()
+	}
+
+	ZeroGrad(root)
+	BackwardParallel(root, workers)
+	for i, n := range nodes {
+		assert.InDelta(t, want[i], n.GetGradient(), 1e-12, "node %d", i)
+	}
+}
+
+func TestBackwardParallel(t *testing.T) {
+	t.Run("matches Backward", func(t *testing.T) {
+		loss, _ := mlp(256, 64)
+		for _, workers := range []int{0, 1, 2, 8} {
+			t.Run(fmt.Sprint(workers), func(t *testing.T) {
+				assertSameGradients(t, loss, workers)
+			})
+		}
+	})
+
+	t.Run("every operation", func(t *testing.T) {
+		a := NewValue(0.7)
+		b := NewValue(1.3)
+		c := NewValue(-0.4)
+		y := a.Mul(b).Sigmoid()
+		out := Sum[float64](
+			y.Mul(y), y.Div(b), c.Tanh().Pow(2), Prod[float64](a, b, c).Exp(),
+			a.PowValue(b), b.Sqrt().Log(), c.Neg().ReLU(), a.Sub(c), a.Add(c),
+			Dot([]Numeric[float64]{a, b}, []Numeric[float64]{c, a}),
+			Apply[float64](hypot{}, a, b), Apply[float64](square{}, c),
+		)
+		assertSameGradients(t, out, 4)
+	})
+
+	t.Run("frozen nodes and stop gradients", func(t *testing.T) {
+		x := NewValue(3.0)
+		k := NewValue(2.0, WithRequiresGrad(false))
+		stop := x.Mul(x).(*Value[float64]).SetRequiresGrad(false)
+		out := x.Mul(stop).Add(k.Exp()).Mul(x)
+
+		BackwardParallel(out, 4)
+
+		// out = x^4 + e^2 x with x^2 held constant: 2 x^3 + e^2
+		assert.InDelta(t, 54+k.Exp().GetValue(), x.GetGradient(), 1e-12)
+		assert.Equal(t, 0.0, k.GetGradient())
+		assert.Equal(t, 0.0, stop.GetGradient())
+	})
+
+	t.Run("hooks", func(t *testing.T) {
+		loss, leaves := mlp(128, 64)
+		var calls atomic.Int64
+		for _, leaf := range leaves {
+			leaf.RegisterHook(func(g float64) float64 {
+				calls.Add(1)
+				return 2 * g
+			})
+		}
+		ZeroGrad(loss)
+		loss.Backward()
+		want := make([]float64, len(leaves))
+		for i, leaf := range leaves {
+			want[i] = leaf.GetGradient()
+		}
+
+		ZeroGrad(loss)
+		BackwardParallel(loss, 4)
+
+		assert.Equal(t, int64(2*len(leaves)), calls.Load())
+		for i, leaf := range leaves {
+			assert.InDelta(t, want[i], leaf.GetGradient(), 1e-12)
+		}
+	})
+
+	t.Run("accumulates like Backward", func(t *testing.T) {
+		x := NewValue(3.0, WithGradient(1.0))
+
+		BackwardParallel(x.Mul(x), 2)
+
+		assert.Equal(t, 7.0, x.GetGradient())
+
+		x.RegisterHook(func(g float64) float64 { return 2 * g })
+		BackwardParallel(x.Mul(x), 2)
+		assert.Equal(t, 19.0, x.GetGradient())
+	})
+}
+
+func BenchmarkBackwardParallel(b *testing.B) {
+	loss, _ := mlp(512, 256)
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ZeroGrad(loss)
+			loss.Backward()
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ZeroGrad(loss)
+			BackwardParallel(loss, 0)
+		}
+	})
+}
@@ -0,0 +1,341 @@
+package micrograd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParseError reports malformed input to Parse.
+type ParseError struct {
+	// Pos is the byte offset in the expression where the problem was found.
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse error at offset %d: %s", e.Pos, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind     tokenKind
+	text     string
+	pos, end int
+}
+
+// parser is a recursive descent parser over the grammar
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/") unary }
+//	unary   = "-" unary | power
+//	power   = primary [ "^" unary ]
+//	primary = number | ident | ident "(" [ expr { "," expr } ] ")" | "(" expr ")"
+//
+// so "^" binds tightest and associates to the right, and -a^2 is -(a^2).
+type parser[K BaseNumeric] struct {
+	src  string
+	vars map[string]*Value[K]
+	tok  token
+	last int // end offset of the previous token
+}
+
+// Parse builds a graph from an infix expression over the variables in vars,
+// which appear in the graph as they are. The operators + - * / ^ and unary
+// minus follow the usual precedence, and tanh, relu, sigmoid, exp, log and
+// sqrt may be called with one argument, sum and prod with any number.
+// Number literals become unnamed constants that do not require gradients.
+//
+// Every node built is named after the subexpression it computes, so Parse(
+// "tanh(a*b + c) - d^2", vars) yields nodes named "a*b", "a*b + c",
+// "tanh(a*b + c)", "d^2" and the whole expression. Malformed input is
+// reported as a *ParseError.
+func Parse[K BaseNumeric](expr string, vars map[string]*Value[K]) (Numeric[K], error) {
+	p := &parser[K]{src: expr, vars: vars}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf(p.tok.pos, "unexpected %q", p.tok.text)
+	}
+	return n, nil
+}
+
+func (p *parser[K]) errorf(pos int, format string, args ...any) error {
+	return &ParseError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next advances to the following token.
+func (p *parser[K]) next() error {
+	p.last = p.tok.end
+	i := p.tok.end
+	for i < len(p.src) {
+		r, size := utf8.DecodeRuneInString(p.src[i:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		i += size
+	}
+	if i == len(p.src) {
+		p.tok = token{kind: tokEOF, pos: i, end: i}
+		return nil
+	}
+
+	start := i
+	c := p.src[i]
+	switch {
+	case isDigit(c) || c == '.':
+		for i < len(p.src) && (isDigit(p.src[i]) || p.src[i] == '.') {
+			i++
+		}
+		if i < len(p.src) && (p.src[i] == 'e' || p.src[i] == 'E') {
+			i++
+			if i < len(p.src) && (p.src[i] == '+' || p.src[i] == '-') {
+				i++
+			}
+			for i < len(p.src) && isDigit(p.src[i]) {
+				i++
+			}
+		}
+		p.tok = token{kind: tokNumber, text: p.src[start:i], pos: start, end: i}
+	case isLetter(c):
+		for i < len(p.src) && (isLetter(p.src[i]) || isDigit(p.src[i])) {
+			i++
+		}
+		p.tok = token{kind: tokIdent, text: p.src[start:i], pos: start, end: i}
+	case strings.IndexByte("+-*/^(),", c) >= 0:
+		p.tok = token{kind: tokOp, text: p.src[start : i+1], pos: start, end: i + 1}
+	default:
+		r, _ := utf8.DecodeRuneInString(p.src[i:])
+		return p.errorf(start, "unexpected character %q", r)
+	}
+	return nil
+}
+
+func isDigit(c byte) bool  { return '0' <= c && c <= '9' }
+func isLetter(c byte) bool { return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' }
+
+// is reports whether the current token is the operator op.
+func (p *parser[K]) is(op string) bool {
+	return p.tok.kind == tokOp && p.tok.text == op
+}
+
+// name labels n with the source text from start up to the previous token.
+func (p *parser[K]) name(n Numeric[K], start int) Numeric[K] {
+	return n.SetName(p.src[start:p.last])
+}
+
+func (p *parser[K]) expr() (Numeric[K], error) {
+	start := p.tok.pos
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("+") || p.is("-") {
+		op := p.tok.text
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		if op == "+" {
+			left = p.name(left.Add(right), start)
+		} else {
+			left = p.name(left.Sub(right), start)
+		}
+	}
+	return left, nil
+}
+
+func (p *parser[K]) term() (Numeric[K], error) {
+	start := p.tok.pos
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("*") || p.is("/") {
+		op := p.tok.text
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "*" {
+			left = p.name(left.Mul(right), start)
+		} else {
+			left = p.name(left.Div(right), start)
+		}
+	}
+	return left, nil
+}
+
+func (p *parser[K]) unary() (Numeric[K], error) {
+	if !p.is("-") {
+		return p.power()
+	}
+	start := p.tok.pos
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	operand, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return p.name(operand.Neg(), start), nil
+}
+
+func (p *parser[K]) power() (Numeric[K], error) {
+	start := p.tok.pos
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.is("^") {
+		return base, nil
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	exponent, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return p.name(base.PowValue(exponent), start), nil
+}
+
+func (p *parser[K]) primary() (Numeric[K], error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokNumber:
+		x, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf(tok.pos, "invalid number %q", tok.text)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		return NewValue(K(x), WithRequiresGrad(false)), nil
+
+	case tok.kind == tokIdent:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.is("(") {
+			return p.call(tok)
+		}
+		v, ok := p.vars[tok.text]
+		if !ok || v == nil {
+			return nil, p.errorf(tok.pos, "unknown variable %q", tok.text)
+		}
+		return v, nil
+
+	case p.is("("):
+		open := tok.pos
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		inner, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.is(")") {
+			return nil, p.expected(")", open)
+		}
+		return inner, p.next()
+
+	case tok.kind == tokEOF:
+		return nil, p.errorf(tok.pos, "unexpected end of expression")
+	default:
+		return nil, p.errorf(tok.pos, "unexpected %q", tok.text)
+	}
+}
+
+// expected reports a missing token, noting where the construct it closes
+// was opened.
+func (p *parser[K]) expected(what string, open int) error {
+	found := "end of expression"
+	if p.tok.kind != tokEOF {
+		found = strconv.Quote(p.tok.text)
+	}
+	return p.errorf(p.tok.pos, "expected %q to match offset %d, found %s", what, open, found)
+}
+
+// call parses the argument list of a function whose name has been consumed.
+func (p *parser[K]) call(fn token) (Numeric[K], error) {
+	switch fn.text {
+	case "sum", "prod", "tanh", "relu", "sigmoid", "exp", "log", "sqrt":
+	default:
+		return nil, p.errorf(fn.pos, "unknown function %q", fn.text)
+	}
+	open := p.tok.pos
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	var args []Numeric[K]
+	if !p.is(")") {
+		for {
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.is(",") {
+				break
+			}
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !p.is(")") {
+		return nil, p.expected(")", open)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	var n Numeric[K]
+	switch fn.text {
+	case "sum":
+		n = Sum(args...)
+	case "prod":
+		n = Prod(args...)
+	case "tanh", "relu", "sigmoid", "exp", "log", "sqrt":
+		if len(args) != 1 {
+			return nil, p.errorf(fn.pos, "%s takes 1 argument, got %d", fn.text, len(args))
+		}
+		switch fn.text {
+		case "tanh":
+			n = args[0].Tanh()
+		case "relu":
+			n = args[0].ReLU()
+		case "sigmoid":
+			n = args[0].Sigmoid()
+		case "exp":
+			n = args[0].Exp()
+		case "log":
+			n = args[0].Log()
+		case "sqrt":
+			n = args[0].Sqrt()
+		}
+	}
+	return p.name(n, fn.pos), nil
+}
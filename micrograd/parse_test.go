@@ -0,0 +1,138 @@
+package micrograd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	vars := func() map[string]*Value[float64] {
+		return map[string]*Value[float64]{
+			"a": NewValue(0.5, WithName("a")),
+			"b": NewValue(-1.5, WithName("b")),
+			"c": NewValue(2.0, WithName("c")),
+			"d": NewValue(3.0, WithName("d")),
+		}
+	}
+
+	t.Run("builds the graph", func(t *testing.T) {
+		vs := vars()
+		a, b, c, d := vs["a"], vs["b"], vs["c"], vs["d"]
+		want := a.Mul(b).Add(c).Tanh().Sub(d.Pow(2))
+		want.Backward()
+		wantGrads := []float64{a.GetGradient(), b.GetGradient(), c.GetGradient(), d.GetGradient()}
+		ZeroGradParams([]*Value[float64]{a, b, c, d})
+
+		out, err := Parse("tanh(a*b + c) - d^2", vs)
+		assert.NoError(t, err)
+		assert.Equal(t, want.GetValue(), out.GetValue())
+
+		out.Backward()
+		assert.Equal(t, wantGrads, []float64{a.GetGradient(), b.GetGradient(), c.GetGradient(), d.GetGradient()})
+	})
+
+	t.Run("names subexpressions", func(t *testing.T) {
+		out, err := Parse(" tanh(a*b + c) - d^2 ", vars())
+		assert.NoError(t, err)
+
+		var names []string
+		for _, n := range TopologicalOrder(out) {
+			names = append(names, n.GetName())
+		}
+		assert.Equal(t, []string{"a", "b", "a*b", "c", "a*b + c", "tanh(a*b + c)", "d", "", "d^2", "tanh(a*b + c) - d^2"}, names)
+	})
+
+	t.Run("precedence and associativity", func(t *testing.T) {
+		for _, tc := range []struct {
+			expr string
+			want float64
+		}{
+			{"1 + 2 * 3", 7},
+			{"(1 + 2) * 3", 9},
+			{"8 - 3 - 2", 3},
+			{"8 / 4 / 2", 1},
+			{"2 ^ 3 ^ 2", 512},
+			{"-2 ^ 2", -4},
+			{"(-2) ^ 2", 4},
+			{"2 ^ -1", 0.5},
+			{"--c", 2},
+			{"c * -d", -6},
+			{"1.5e1 + .5", 15.5},
+			{"sum(a, b, c) * prod(c, d)", 6},
+			{"sum()", 0},
+			{"sqrt(d * 3) + exp(log(c)) + relu(b) + sigmoid(0)", 5.5},
+		} {
+			t.Run(tc.expr, func(t *testing.T) {
+				out, err := Parse(tc.expr, vars())
+				assert.NoError(t, err)
+				assert.InDelta(t, tc.want, out.GetValue(), 1e-12)
+			})
+		}
+	})
+
+	t.Run("constants do not require gradients", func(t *testing.T) {
+		out, err := Parse("a * 2", vars())
+		assert.NoError(t, err)
+		two := out.GetChildren()[1]
+		assert.Equal(t, "", two.GetName())
+		assert.False(t, two.RequiresGrad())
+	})
+
+	t.Run("a single variable", func(t *testing.T) {
+		vs := vars()
+		out, err := Parse("(a)", vs)
+		assert.NoError(t, err)
+		assert.Same(t, vs["a"], out)
+	})
+
+	t.Run("float32", func(t *testing.T) {
+		x := NewValue[float32](2, WithName("x"))
+		out, err := Parse("x^2 + 1", map[string]*Value[float32]{"x": x})
+		assert.NoError(t, err)
+		out.Backward()
+		assert.Equal(t, float32(5), out.GetValue())
+		assert.Equal(t, float32(4), x.GetGradient())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			expr string
+			pos  int
+			msg  string
+		}{
+			{"", 0, "unexpected end of expression"},
+			{"a +", 3, "unexpected end of expression"},
+			{"a + * b", 4, `unexpected "*"`},
+			{"a b", 2, `unexpected "b"`},
+			{"a $ b", 2, `unexpected character '$'`},
+			{"a + é", 4, `unexpected character 'é'`},
+			{"a +\u00a0é", 5, `unexpected character 'é'`},
+			{"x + 1", 0, `unknown variable "x"`},
+			{"a + foo(b)", 4, `unknown function "foo"`},
+			{"tanh(a, b)", 0, "tanh takes 1 argument, got 2"},
+			{"(a + b", 6, `expected ")" to match offset 0, found end of expression`},
+			{"exp(a b)", 6, `expected ")" to match offset 3, found "b"`},
+			{"1.2.3", 0, `invalid number "1.2.3"`},
+			{"a)", 1, `unexpected ")"`},
+		} {
+			t.Run(tc.expr, func(t *testing.T) {
+				_, err := Parse(tc.expr, vars())
+				assert.Equal(t, &ParseError{Pos: tc.pos, Msg: tc.msg}, err)
+			})
+		}
+		_, err := Parse("a +", vars())
+		assert.EqualError(t, err, "parse error at offset 3: unexpected end of expression")
+	})
+
+	t.Run("agrees with gradient checking", func(t *testing.T) {
+		report := GradCheck(func(leaves []*Value[float64]) Numeric[float64] {
+			out, err := Parse("sigmoid(x*y - log(x)) / sqrt(y) + x^y", map[string]*Value[float64]{"x": leaves[0], "y": leaves[1]})
+			assert.NoError(t, err)
+			return out
+		}, []*Value[float64]{NewValue(1.3), NewValue(0.4)}, 1e-6, 1e-6)
+		assert.True(t, report.OK(), report.String())
+		assert.False(t, math.IsNaN(report.MaxRelError))
+	})
+}
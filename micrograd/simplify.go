@@ -0,0 +1,156 @@
+package micrograd
+
+import "slices"
+
+// Simplify returns an equivalent, usually smaller, graph computing root. The
+// original graph is left untouched: interior nodes are rebuilt, while leaves
+// other than folded constants are shared with it, so gradients still reach
+// the same parameters.
+//
+// Constants are unnamed leaves that do not require gradients, such as number
+// literals from Parse or the exponent of Pow. Subtrees made only of constants
+// are folded into a single constant, constant terms of sums and factors of
+// products are combined, and identities such as x+0, x-0, x*1, x/1, x^1 and
+// -(-x) are removed. Rewrites that only hold for finite inputs, like x*0,
+// are not applied. Rebuilt nodes keep their names, stop-gradient flags and
+// hooks, and nodes cleared with SetRequiresGrad(false) or carrying hooks are
+// never rewritten away.
+//
+// A named root keeps its name. When it simplifies to a leaf of the original
+// graph, as x+0 does to x, that leaf is wrapped in a single-term sum carrying
+// the name rather than renamed.
+func Simplify[K BaseNumeric](root Numeric[K]) Numeric[K] {
+	nodes := TopologicalOrder(root)
+	simplified := make(map[Numeric[K]]Numeric[K], len(nodes))
+	for _, n := range nodes {
+		children := n.GetChildren()
+		if len(children) == 0 {
+			simplified[n] = n
+			continue
+		}
+		cs := make([]Numeric[K], len(children))
+		for i, c := range children {
+			cs[i] = simplified[c]
+		}
+		simplified[n] = simplifyNode(n, cs)
+	}
+
+	out := simplified[root]
+	if name := root.GetName(); name != "" && out.GetName() != name {
+		if _, shared := simplified[out]; shared {
+			out = adopt(SUM, []Numeric[K]{out})
+		}
+		out.SetName(name)
+	}
+	return out
+}
+
+// simplifyNode rewrites n given its already simplified children.
+func simplifyNode[K BaseNumeric](n Numeric[K], cs []Numeric[K]) Numeric[K] {
+	op := n.GetOperation()
+	if allConstant(cs) {
+		return constant(evaluate(op, cs))
+	}
+	if stopped(n) || hooked(n) {
+		return rebuild(n, op, cs)
+	}
+
+	switch op {
+	case ADD:
+		if isConstantValue(cs[1], 0) {
+			return cs[0]
+		}
+		if isConstantValue(cs[0], 0) {
+			return cs[1]
+		}
+	case SUB:
+		if isConstantValue(cs[1], 0) {
+			return cs[0]
+		}
+		if isConstantValue(cs[0], 0) {
+			return rebuild(n, NEG, cs[1:])
+		}
+	case MUL:
+		if isConstantValue(cs[1], 1) {
+			return cs[0]
+		}
+		if isConstantValue(cs[0], 1) {
+			return cs[1]
+		}
+	case DIV, POW:
+		if isConstantValue(cs[1], 1) {
+			return cs[0]
+		}
+	case NEG:
+		if inner := cs[0]; inner.GetOperation() == NEG && !stopped(inner) && !hooked(inner) {
+			return inner.GetChildren()[0]
+		}
+	case SUM:
+		cs = combineConstants(SUM, cs, 0)
+		if len(cs) == 1 {
+			return cs[0]
+		}
+	case PROD:
+		cs = combineConstants(PROD, cs, 1)
+		if len(cs) == 1 {
+			return cs[0]
+		}
+	}
+	return rebuild(n, op, cs)
+}
+
+// combineConstants replaces the constant operands of an n-ary sum or product
+// with a single one, dropped altogether when it equals the identity element.
+func combineConstants[K BaseNumeric](op OperationEnum, cs []Numeric[K], identity K) []Numeric[K] {
+	var rest, constants []Numeric[K]
+	for _, c := range cs {
+		if isConstant(c) {
+			constants = append(constants, c)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+	if len(constants) == 0 {
+		return cs
+	}
+	if x := evaluate(op, constants); x != identity {
+		rest = append(rest, constant(x))
+	}
+	return rest
+}
+
+// rebuild creates a copy of n with a new operation and children. The copy
+// runs the same hooks as n; removing one through its HookHandle afterwards
+// only affects n.
+func rebuild[K BaseNumeric](n Numeric[K], op OperationEnum, cs []Numeric[K]) *Value[K] {
+	v := adopt(op, cs)
+	v.Name = n.GetName()
+	v.noGrad = stopped(n)
+	if orig, ok := n.(*Value[K]); ok {
+		v.hooks = slices.Clone(orig.hooks)
+	}
+	return v
+}
+
+func constant[K BaseNumeric](x K) *Value[K] {
+	return NewValue(x, WithRequiresGrad(false))
+}
+
+// isConstant reports whether n is an unnamed leaf that does not require
+// gradients.
+func isConstant[K BaseNumeric](n Numeric[K]) bool {
+	return len(n.GetChildren()) == 0 && !n.RequiresGrad() && n.GetName() == ""
+}
+
+func isConstantValue[K BaseNumeric](n Numeric[K], x K) bool {
+	return isConstant(n) && n.GetValue() == x
+}
+
+func allConstant[K BaseNumeric](ns []Numeric[K]) bool {
+	for _, n := range ns {
+		if !isConstant(n) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+package micrograd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimplify(t *testing.T) {
+	one := func() *Value[float64] { return NewValue(1.0, WithRequiresGrad(false)) }
+	zero := func() *Value[float64] { return NewValue(0.0, WithRequiresGrad(false)) }
+
+	t.Run("identities", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		for name, build := range map[string]func() Numeric[float64]{
+			"x+0":   func() Numeric[float64] { return x.Add(zero()) },
+			"0+x":   func() Numeric[float64] { return zero().Add(x) },
+			"x-0":   func() Numeric[float64] { return x.Sub(zero()) },
+			"x*1":   func() Numeric[float64] { return x.Mul(one()) },
+			"1*x":   func() Numeric[float64] { return one().Mul(x) },
+			"x/1":   func() Numeric[float64] { return x.Div(one()) },
+			"x^1":   func() Numeric[float64] { return x.Pow(1) },
+			"-(-x)": func() Numeric[float64] { return x.Neg().Neg() },
+			"sum":   func() Numeric[float64] { return Sum[float64](zero(), x, zero()) },
+			"prod":  func() Numeric[float64] { return Prod[float64](one(), x) },
+		} {
+			t.Run(name, func(t *testing.T) {
+				assert.Same(t, x, Simplify(build()))
+			})
+		}
+	})
+
+	t.Run("constant folding", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		two := NewValue(2.0, WithRequiresGrad(false))
+		out := x.Mul(two.Mul(two).Sub(one().Mul(NewValue(4.0, WithRequiresGrad(false)))).Exp())
+
+		s := Simplify(out)
+
+		// exp(2*2 - 1*4) = 1
+		assert.Same(t, x, s)
+	})
+
+	t.Run("0-x becomes a negation", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		s := Simplify(zero().Sub(x).SetName("y"))
+
+		assert.Equal(t, OperationEnum(NEG), s.GetOperation())
+		assert.Equal(t, "y", s.GetName())
+		assert.Equal(t, -3.0, s.GetValue())
+	})
+
+	t.Run("sums and products combine their constants", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		y := NewValue(5.0, WithName("y"))
+		k := NewValue(2.0, WithRequiresGrad(false))
+
+		s := Simplify(Sum[float64](k, x, k, y, NewValue(-4.0, WithRequiresGrad(false))))
+		assert.Equal(t, []Numeric[float64]{x, y}, s.GetChildren())
+
+		p := Simplify(Prod[float64](k, x, k, y))
+		children := p.GetChildren()
+		assert.Len(t, children, 3)
+		assert.Equal(t, 4.0, children[2].GetValue())
+		assert.Equal(t, 60.0, p.GetValue())
+	})
+
+	t.Run("named and trainable leaves are kept", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		frozen := NewValue(1.0, WithName("k"), WithRequiresGrad(false))
+		w := NewValue(0.0)
+
+		s := Simplify(x.Mul(frozen).Add(w))
+
+		assert.Equal(t, OperationEnum(ADD), s.GetOperation())
+		assert.Same(t, w, s.GetChildren()[1])
+		assert.Equal(t, []Numeric[float64]{x, frozen}, s.GetChildren()[0].GetChildren())
+	})
+
+	t.Run("stop gradients are preserved", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		stop := x.Mul(one()).(*Value[float64]).SetRequiresGrad(false)
+		out := x.Mul(stop)
+
+		s := Simplify(out)
+		s.Backward()
+
+		assert.False(t, s.GetChildren()[1].RequiresGrad())
+		assert.Equal(t, 3.0, x.GetGradient())
+	})
+
+	t.Run("hooks are kept", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		y := x.Mul(one()).(*Value[float64])
+		var seen []float64
+		y.RegisterHook(func(g float64) float64 {
+			seen = append(seen, g)
+			return g
+		})
+		out := y.Mul(x)
+
+		s := Simplify(out)
+		s.Backward()
+
+		assert.Equal(t, []float64{3}, seen)
+		assert.Equal(t, 6.0, x.GetGradient())
+	})
+
+	t.Run("named root keeps its name", func(t *testing.T) {
+		x := NewValue(3.0, WithName("x"))
+		yy := x.Mul(x).SetName("yy")
+
+		assert.Equal(t, "root", Simplify(yy.Add(zero()).SetName("root")).GetName())
+
+		s := Simplify(x.Add(zero()).SetName("root"))
+		assert.Equal(t, "root", s.GetName())
+		assert.Equal(t, "x", x.GetName())
+		s.Backward()
+		assert.Equal(t, 1.0, x.GetGradient())
+	})
+
+	t.Run("original graph is untouched", func(t *testing.T) {
+		x := NewValue(0.5, WithName("x"))
+		y := NewValue(-1.5, WithName("y"))
+		h := x.Mul(y).Add(zero()).Tanh().SetName("h")
+		out := h.Mul(h).Mul(one()).Add(h).SetName("out")
+		before := len(TopologicalOrder(out))
+
+		s := Simplify(out)
+
+		assert.Len(t, TopologicalOrder(out), before)
+		assert.Less(t, len(TopologicalOrder(s)), before)
+		assert.Equal(t, "out", s.GetName())
+		assert.Equal(t, out.GetValue(), s.GetValue())
+
+		// h is shared in the simplified graph as well
+		var shared *Value[float64]
+		for _, n := range TopologicalOrder(s) {
+			if n.GetName() == "h" {
+				assert.Nil(t, shared)
+				shared = n.(*Value[float64])
+			}
+		}
+		assert.NotNil(t, shared)
+		assert.NotSame(t, h, shared)
+
+		out.Backward()
+		want := []float64{x.GetGradient(), y.GetGradient()}
+		hGrad := h.GetGradient()
+		ZeroGradParams([]*Value[float64]{x, y})
+		s.Backward()
+		assert.InDeltaSlice(t, want, []float64{x.GetGradient(), y.GetGradient()}, 1e-12)
+		assert.Equal(t, hGrad, h.GetGradient())
+	})
+
+	t.Run("fully constant root keeps its name", func(t *testing.T) {
+		out, err := Parse("2 * 3 + 1", map[string]*Value[float64]{})
+		assert.NoError(t, err)
+
+		s := Simplify(out)
+
+		assert.Empty(t, s.GetChildren())
+		assert.Equal(t, 7.0, s.GetValue())
+		assert.Equal(t, "2 * 3 + 1", s.GetName())
+	})
+
+	t.Run("parsed expressions", func(t *testing.T) {
+		a := NewValue(0.5, WithName("a"))
+		out, err := Parse("1 * (a + 0) ^ (3 - 2) + 2 * 0.5 * a", map[string]*Value[float64]{"a": a})
+		assert.NoError(t, err)
+
+		s := Simplify(out)
+
+		assert.Equal(t, out.GetValue(), s.GetValue())
+		// a + a
+		assert.Len(t, TopologicalOrder(s), 2)
+		s.Backward()
+		assert.Equal(t, 2.0, a.GetGradient())
+	})
+}
@@ -2,6 +2,7 @@ package micrograd
 
 import (
 	"fmt"
+	"math"
 
 	"golang.org/x/exp/constraints"
 )
@@ -13,8 +14,48 @@ const (
 	ADD   = '+'
 	SUB   = '-'
 	MUL   = '*'
+	DIV   = '/'
+	POW   = '^'
+	NEG   = '−'
+	SUM   = 'Σ'
+	PROD  = 'Π'
+	DOT   = '·'
 )
 
+const (
+	TANH = iota + 0x100
+	RELU
+	SIGMOID
+	EXP
+	LOG
+	SQRT
+)
+
+// String returns the symbol used to display the operation.
+func (o OperationEnum) String() string {
+	switch o {
+	case UNSET:
+		return ""
+	case TANH:
+		return "tanh"
+	case RELU:
+		return "relu"
+	case SIGMOID:
+		return "σ"
+	case EXP:
+		return "exp"
+	case LOG:
+		return "log"
+	case SQRT:
+		return "√"
+	default:
+		if symbol, ok := customSymbol(o); ok {
+			return symbol
+		}
+		return string(rune(o))
+	}
+}
+
 type BaseNumeric interface {
 	constraints.Float
 }
@@ -25,12 +66,28 @@ type Numeric[K BaseNumeric] interface {
 	Add(Numeric[K]) Numeric[K]
 	Sub(Numeric[K]) Numeric[K]
 	Mul(Numeric[K]) Numeric[K]
+	Div(Numeric[K]) Numeric[K]
+	Pow(K) Numeric[K]
+	PowValue(Numeric[K]) Numeric[K]
+	Neg() Numeric[K]
+	Tanh() Numeric[K]
+	ReLU() Numeric[K]
+	Sigmoid() Numeric[K]
+	Exp() Numeric[K]
+	Log() Numeric[K]
+	Sqrt() Numeric[K]
 	GetValue() K
 	SetValue(K) *Value[K]
 	GetGradient() K
 	SetGradient(K) *Value[K]
-	GetChildren() Pair[Numeric[K]]
+	GetChildren() []Numeric[K]
 	GetOperation() OperationEnum
+	RequiresGrad() bool
+	SetRequiresGrad(bool) *Value[K]
+	Detach() *Value[K]
+	RegisterHook(func(grad K) K) HookHandle
+	Forward() K
+	Backward()
 	Backtrack()
 }
 
@@ -39,33 +96,107 @@ type Value[K BaseNumeric] struct {
 
 	datum     K
 	gradient  K
-	children  Pair[Numeric[K]]
+	children  []Numeric[K]
 	operation OperationEnum
+	noGrad    bool
+	hooks     []*hook[K]
 }
 
-var _ Numeric[float64] = NewValue(0.0)
+var (
+	_ Numeric[float32] = NewValue[float32](0)
+	_ Numeric[float64] = NewValue[float64](0)
+)
 
-func (v *Value[K]) Add(input Numeric[K]) Numeric[K] {
+// newNode builds an interior node from a copy of children, computing its
+// value from them.
+func newNode[K BaseNumeric](op OperationEnum, children ...Numeric[K]) *Value[K] {
+	return adopt(op, append([]Numeric[K](nil), children...))
+}
+
+// adopt builds an interior node that takes ownership of children.
+func adopt[K BaseNumeric](op OperationEnum, children []Numeric[K]) *Value[K] {
 	return &Value[K]{
-		datum:     v.datum + input.GetValue(),
-		children:  NewPair[Numeric[K]](v, input),
-		operation: ADD,
+		datum:     evaluate(op, children),
+		operation: op,
+		children:  children,
 	}
 }
 
+func (v *Value[K]) Add(input Numeric[K]) Numeric[K] {
+	return newNode(ADD, v, input)
+}
+
 func (v *Value[K]) Sub(k Numeric[K]) Numeric[K] {
-	return &Value[K]{
-		datum:     v.datum - k.GetValue(),
-		operation: SUB,
-		children:  NewPair[Numeric[K]](v, k),
-	}
+	return newNode(SUB, v, k)
 }
 
 func (v *Value[K]) Mul(k Numeric[K]) Numeric[K] {
-	return &Value[K]{
-		datum:     v.datum * k.GetValue(),
-		operation: MUL,
-		children:  NewPair[Numeric[K]](v, k),
+	return newNode(MUL, v, k)
+}
+
+func (v *Value[K]) Div(k Numeric[K]) Numeric[K] {
+	return newNode(DIV, v, k)
+}
+
+// Pow raises v to a constant exponent.
+func (v *Value[K]) Pow(exponent K) Numeric[K] {
+	return v.PowValue(NewValue(exponent, WithRequiresGrad(false)))
+}
+
+// PowValue raises v to an exponent that is itself part of the graph.
+func (v *Value[K]) PowValue(k Numeric[K]) Numeric[K] {
+	return newNode(POW, v, k)
+}
+
+func (v *Value[K]) Neg() Numeric[K] {
+	return newNode[K](NEG, v)
+}
+
+func (v *Value[K]) Tanh() Numeric[K] {
+	return newNode[K](TANH, v)
+}
+
+func (v *Value[K]) ReLU() Numeric[K] {
+	return newNode[K](RELU, v)
+}
+
+func (v *Value[K]) Sigmoid() Numeric[K] {
+	return newNode[K](SIGMOID, v)
+}
+
+func (v *Value[K]) Exp() Numeric[K] {
+	return newNode[K](EXP, v)
+}
+
+func (v *Value[K]) Log() Numeric[K] {
+	return newNode[K](LOG, v)
+}
+
+func (v *Value[K]) Sqrt() Numeric[K] {
+	return newNode[K](SQRT, v)
+}
+
+// Sum adds any number of values in a single node.
+func Sum[K BaseNumeric](values ...Numeric[K]) Numeric[K] {
+	return newNode(SUM, values...)
+}
+
+// Prod multiplies any number of values in a single node.
+func Prod[K BaseNumeric](values ...Numeric[K]) Numeric[K] {
+	return newNode(PROD, values...)
+}
+
+// Dot computes the inner product of ws and xs in a single node. It panics if
+// the slices differ in length.
+func Dot[K BaseNumeric](ws, xs []Numeric[K]) Numeric[K] {
+	checkDot(ws, xs)
+	children := append(append(make([]Numeric[K], 0, 2*len(ws)), ws...), xs...)
+	return adopt(DOT, children)
+}
+
+func checkDot[K BaseNumeric](ws, xs []Numeric[K]) {
+	if len(ws) != len(xs) {
+		panic(fmt.Sprintf("dot product of mismatched lengths %d and %d", len(ws), len(xs)))
 	}
 }
 
@@ -87,7 +218,9 @@ func (v *Value[K]) SetValue(input K) *Value[K] {
 	return v
 }
 
-func (v *Value[K]) GetChildren() Pair[Numeric[K]] {
+// GetChildren returns the inputs of the node, in operand order. Leaves have
+// none.
+func (v *Value[K]) GetChildren() []Numeric[K] {
 	return v.children
 }
 
@@ -95,6 +228,34 @@ func (v *Value[K]) GetOperation() OperationEnum {
 	return v.operation
 }
 
+// RequiresGrad reports whether backpropagation computes a gradient for v.
+// Leaves require gradients unless created with WithRequiresGrad(false) or
+// frozen with SetRequiresGrad. An interior node requires gradients when it
+// has not been cleared itself and some leaf below it, reached without
+// crossing a cleared node, does; this walks the graph under v, and always
+// reflects the current flags of its leaves.
+func (v *Value[K]) RequiresGrad() bool {
+	if v.operation == UNSET {
+		return !v.noGrad
+	}
+	return trainable(TopologicalOrder[K](v))[v]
+}
+
+// SetRequiresGrad sets whether v takes part in backpropagation. Clearing it
+// freezes a leaf parameter, or stops gradients flowing through an interior
+// node into the subgraph below it. Setting it again undoes either, so frozen
+// parameters can be fine-tuned later in a graph that is reused.
+func (v *Value[K]) SetRequiresGrad(requires bool) *Value[K] {
+	v.noGrad = !requires
+	return v
+}
+
+// Detach returns a new leaf holding the current value and name of v, cut off
+// from the graph and with no gradient required.
+func (v *Value[K]) Detach() *Value[K] {
+	return NewValue(v.datum, WithName(v.Name), WithRequiresGrad(false))
+}
+
 func (v *Value[K]) String() string {
 	return fmt.Sprintf("Value(data=%v)", v.GetValue())
 }
@@ -108,14 +269,171 @@ func (v *Value[K]) SetGradient(input K) *Value[K] {
 	return v
 }
 
+// Forward re-evaluates every intermediate node of the graph from the current
+// values of its leaves, in topological order, and returns the new value of
+// v. This lets a graph be built once and reused after SetValue on its leaves.
+func (v *Value[K]) Forward() K {
+	for _, n := range TopologicalOrder[K](v) {
+		if children := n.GetChildren(); len(children) > 0 {
+			n.SetValue(evaluate(n.GetOperation(), children))
+		}
+	}
+	return v.GetValue()
+}
+
+// Backward seeds the gradient of v with 1 and backpropagates it through the
+// whole graph, so that every node ends up holding dv/dnode.
+func (v *Value[K]) Backward() {
+	v.SetGradient(1)
+	v.Backtrack()
+}
+
+// Backtrack propagates the current gradient of v down to every node of its
+// graph. Nodes are visited in reverse topological order, so each node passes
+// its gradient on exactly once, after all of its parents have contributed.
+// Subgraphs with no leaf that requires gradients are skipped entirely.
+// Hooks registered on a node run just before it passes its gradient on.
+//
+// Intermediate nodes start each pass from zero, so they end up holding the
+// gradient of this pass only, while leaves accumulate across passes. Hooks
+// only ever see the gradient of the current pass.
 func (v *Value[K]) Backtrack() {
+	nodes := TopologicalOrder[K](v)
+	need := trainable(nodes)
+	prior := make(map[Numeric[K]]K)
+	for _, n := range nodes {
+		if n == Numeric[K](v) || !need[n] {
+			continue
+		}
+		if len(n.GetChildren()) == 0 {
+			prior[n] = n.GetGradient()
+		}
+		n.SetGradient(0)
+	}
+	for i := len(nodes) - 1; i >= 0; i-- {
+		n := nodes[i]
+		if !need[n] {
+			continue
+		}
+		runHooks(n)
+		if g, ok := prior[n]; ok {
+			n.SetGradient(g + n.GetGradient())
+		}
+		children := n.GetChildren()
+		propagate(n, func(j int, g K) {
+			if c := children[j]; need[c] {
+				c.SetGradient(c.GetGradient() + g)
+			}
+		})
+	}
+}
+
+// Trainable reports which nodes of the graph rooted at root backpropagation
+// computes a gradient for. Tools that differentiate a graph by other means
+// use it to agree with Backward.
+func Trainable[K BaseNumeric](root Numeric[K]) map[Numeric[K]]bool {
+	return trainable(TopologicalOrder(root))
+}
+
+// trainable reports which of nodes, given in topological order, lead to a
+// leaf that requires gradients without passing through a node that was
+// cleared with SetRequiresGrad(false).
+func trainable[K BaseNumeric](nodes []Numeric[K]) map[Numeric[K]]bool {
+	need := make(map[Numeric[K]]bool, len(nodes))
+	for _, n := range nodes {
+		if stopped(n) {
+			continue
+		}
+		if n.GetOperation() == UNSET {
+			need[n] = true
+			continue
+		}
+		for _, c := range n.GetChildren() {
+			if need[c] {
+				need[n] = true
+				break
+			}
+		}
+	}
+	return need
+}
+
+// stopped reports whether n has been explicitly excluded from
+// backpropagation, as opposed to merely having no trainable leaves below it.
+func stopped[K BaseNumeric](n Numeric[K]) bool {
+	v, ok := n.(*Value[K])
+	return ok && v.noGrad
+}
+
+// evaluate computes the value of an operation from the current values of its
+// children.
+func evaluate[K BaseNumeric](op OperationEnum, children []Numeric[K]) K {
+	value := func(i int) float64 { return float64(children[i].GetValue()) }
+
+	switch op {
+	case ADD:
+		return children[0].GetValue() + children[1].GetValue()
+	case SUB:
+		return children[0].GetValue() - children[1].GetValue()
+	case MUL:
+		return children[0].GetValue() * children[1].GetValue()
+	case DIV:
+		return children[0].GetValue() / children[1].GetValue()
+	case POW:
+		return K(math.Pow(value(0), value(1)))
+	case NEG:
+		return -children[0].GetValue()
+	case TANH:
+		return K(math.Tanh(value(0)))
+	case RELU:
+		return max(children[0].GetValue(), 0)
+	case SIGMOID:
+		return K(1 / (1 + math.Exp(-value(0))))
+	case EXP:
+		return K(math.Exp(value(0)))
+	case LOG:
+		return K(math.Log(value(0)))
+	case SQRT:
+		return K(math.Sqrt(value(0)))
+	case SUM:
+		var out K
+		for _, c := range children {
+			out += c.GetValue()
+		}
+		return out
+	case PROD:
+		out := K(1)
+		for _, c := range children {
+			out *= c.GetValue()
+		}
+		return out
+	case DOT:
+		n := len(children) / 2
+		var out K
+		for i := 0; i < n; i++ {
+			out += children[i].GetValue() * children[n+i].GetValue()
+		}
+		return out
+	default:
+		return mustLookupOp[K](op).Forward(values(children))
+	}
+}
+
+// propagate applies the local chain rule of a single node, passing the
+// gradient due to each child, identified by its index, to accumulate.
+func propagate[K BaseNumeric](v Numeric[K], accumulate func(child int, g K)) {
 	// have: dO[utput]/dv
 	// want: dO/da, dO/db -- i.e. we want to know how each leaf node (input)
 	// 		 affects the overall output of the system
-	a, b := v.GetChildren().first, v.GetChildren().second
-	if a == nil && b == nil {
+	children := v.GetChildren()
+	if len(children) == 0 {
 		return
 	}
+	a := children[0]
+	var b Numeric[K]
+	if len(children) > 1 {
+		b = children[1]
+	}
 
 	switch v.GetOperation() {
 	case ADD:
@@ -123,66 +441,164 @@ func (v *Value[K]) Backtrack() {
 		// dv/da = 1
 		// dv/da * dO/dv = dv/da
 		// 1 *
-		a.SetGradient(a.GetGradient() + 1*v.GetGradient())
+		accumulate(0, 1*v.GetGradient())
 		// dv/db = 1
-		b.SetGradient(b.GetGradient() + 1*v.GetGradient())
+		accumulate(1, 1*v.GetGradient())
+	case SUB:
+		// a - b
+		// dv/da = 1
+		accumulate(0, v.GetGradient())
+		// dv/db = -1
+		accumulate(1, -v.GetGradient())
 	case MUL:
 		// a * b
 		// dv/da = b
 		// dv/da * dO/dv = d0/da
-		a.SetGradient(a.GetGradient() + b.GetValue()*v.GetGradient())
+		accumulate(0, b.GetValue()*v.GetGradient())
 		// dv/db = a
 		// dv/db * dO/dv = d0/db
-		b.SetGradient(b.GetGradient() + a.GetValue()*v.GetGradient())
+		accumulate(1, a.GetValue()*v.GetGradient())
+	case DIV:
+		// a / b
+		// dv/da = 1/b
+		accumulate(0, v.GetGradient()/b.GetValue())
+		// dv/db = -a/b^2
+		accumulate(1, -v.GetGradient()*a.GetValue()/(b.GetValue()*b.GetValue()))
+	case POW:
+		// a ^ b
+		// dv/da = b * a^(b-1)
+		base, exponent := float64(a.GetValue()), float64(b.GetValue())
+		accumulate(0, v.GetGradient()*K(exponent*math.Pow(base, exponent-1)))
+		// dv/db = a^b * ln(a), only defined for a positive base
+		if base > 0 {
+			accumulate(1, v.GetGradient()*v.GetValue()*K(math.Log(base)))
+		}
+	case NEG:
+		// -a
+		// dv/da = -1
+		accumulate(0, -v.GetGradient())
+	case TANH:
+		// tanh(a)
+		// dv/da = 1 - tanh(a)^2
+		accumulate(0, v.GetGradient()*(1-v.GetValue()*v.GetValue()))
+	case RELU:
+		// max(a, 0)
+		// dv/da = 1 if a > 0, else 0
+		if a.GetValue() > 0 {
+			accumulate(0, v.GetGradient())
+		}
+	case SIGMOID:
+		// 1 / (1 + e^-a)
+		// dv/da = v * (1 - v)
+		accumulate(0, v.GetGradient()*v.GetValue()*(1-v.GetValue()))
+	case EXP:
+		// e^a
+		// dv/da = e^a
+		accumulate(0, v.GetGradient()*v.GetValue())
+	case LOG:
+		// ln(a)
+		// dv/da = 1/a
+		accumulate(0, v.GetGradient()/a.GetValue())
+	case SQRT:
+		// sqrt(a)
+		// dv/da = 1 / (2 * sqrt(a))
+		accumulate(0, v.GetGradient()/(2*v.GetValue()))
+	case SUM:
+		// a + b + c + ...
+		// dv/di = 1
+		for i := range children {
+			accumulate(i, v.GetGradient())
+		}
+	case PROD:
+		// a * b * c * ...
+		// dv/di = product of every other factor, computed from prefix and
+		// suffix products so that zero factors are handled exactly
+		suffix := make([]K, len(children)+1)
+		suffix[len(children)] = 1
+		for i := len(children) - 1; i >= 0; i-- {
+			suffix[i] = suffix[i+1] * children[i].GetValue()
+		}
+		prefix := K(1)
+		for i, c := range children {
+			accumulate(i, v.GetGradient()*prefix*suffix[i+1])
+			prefix *= c.GetValue()
+		}
+	case DOT:
+		// w0*x0 + w1*x1 + ..., children are w0..wn followed by x0..xn
+		// dv/dwi = xi, dv/dxi = wi
+		n := len(children) / 2
+		ws, xs := children[:n], children[n:]
+		for i := range ws {
+			accumulate(i, v.GetGradient()*xs[i].GetValue())
+			accumulate(n+i, v.GetGradient()*ws[i].GetValue())
+		}
+	default:
+		// registered operation: dv/di comes from the op itself
+		op := mustLookupOp[K](v.GetOperation())
+		grads := op.Gradients(values(children), v.GetValue())
+		for i := range children {
+			accumulate(i, v.GetGradient()*grads[i])
+		}
 	}
-
-	// continue with the rest of the backtrack logic
-	a.Backtrack()
-	b.Backtrack()
 }
 
-type ValueOptions[K BaseNumeric] struct {
+// ValueOptions collects the settings applied by ValueOpt functions. Values
+// are held as float64, which represents every BaseNumeric type exactly.
+type ValueOptions struct {
 	Name     string
-	Value    K
-	Gradient K
+	Value    float64
+	Gradient float64
+	NoGrad   bool
 }
 
-type ValueOpt[K BaseNumeric] func(*ValueOptions[K])
+// ValueOpt configures a Value created by NewValue. Options are not tied to a
+// float type, so the same option works for NewValue[float32] and
+// NewValue[float64].
+type ValueOpt func(*ValueOptions)
 
-func WithName(name string) ValueOpt[float64] {
-	return func(cur *ValueOptions[float64]) {
+func WithName(name string) ValueOpt {
+	return func(cur *ValueOptions) {
 		cur.Name = name
 	}
 }
 
-func WithGradient(input float64) ValueOpt[float64] {
-	return func(cur *ValueOptions[float64]) {
-		cur.Gradient = input
+func WithGradient[K BaseNumeric](input K) ValueOpt {
+	return func(cur *ValueOptions) {
+		cur.Gradient = float64(input)
+	}
+}
+
+func WithValue[K BaseNumeric](input K) ValueOpt {
+	return func(cur *ValueOptions) {
+		cur.Value = float64(input)
 	}
 }
 
-func WithValue(input float64) ValueOpt[float64] {
-	return func(cur *ValueOptions[float64]) {
-		cur.Value = input
+// WithRequiresGrad sets whether the value takes part in backpropagation.
+// Constants and frozen parameters pass false.
+func WithRequiresGrad(requires bool) ValueOpt {
+	return func(cur *ValueOptions) {
+		cur.NoGrad = !requires
 	}
 }
 
-func NewValue[K BaseNumeric](input K, options ...ValueOpt[K]) *Value[K] {
-	opts := &ValueOptions[K]{}
+func NewValue[K BaseNumeric](input K, options ...ValueOpt) *Value[K] {
+	opts := &ValueOptions{}
 	for _, o := range options {
 		o(opts)
 	}
 	v := &Value[K]{
-		datum: input,
+		datum:  input,
+		noGrad: opts.NoGrad,
 	}
 	if opts.Name != "" {
 		v.SetName(opts.Name)
 	}
 	if opts.Gradient != 0 {
-		v.SetGradient(opts.Gradient)
+		v.SetGradient(K(opts.Gradient))
 	}
 	if opts.Value != 0 {
-		v.SetValue(opts.Value)
+		v.SetValue(K(opts.Value))
 	}
 	return v
 }
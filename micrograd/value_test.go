@@ -1,16 +1,31 @@
 package micrograd
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// tolerance returns the absolute error allowed when comparing results that
+// are not exactly representable in K.
+func tolerance[K BaseNumeric]() float64 {
+	if _, ok := any(K(0)).(float32); ok {
+		return 1e-5
+	}
+	return 1e-12
+}
+
 func TestValue_Basic(t *testing.T) {
+	t.Run("float32", testValueBasic[float32])
+	t.Run("float64", testValueBasic[float64])
+}
+
+func testValueBasic[K BaseNumeric](t *testing.T) {
 	tests := []struct {
 		name     string
-		value    float64
-		gradient float64
+		value    K
+		gradient K
 	}{
 		{
 			name:     "simple value",
@@ -34,34 +49,62 @@ func TestValue_Basic(t *testing.T) {
 }
 
 func TestValue_Operations(t *testing.T) {
+	t.Run("float32", testValueOperations[float32])
+	t.Run("float64", testValueOperations[float64])
+}
+
+func testValueOperations[K BaseNumeric](t *testing.T) {
 	t.Run("addition", func(t *testing.T) {
-		a := NewValue(2.0)
-		b := NewValue(3.0)
+		a := NewValue[K](2.0)
+		b := NewValue[K](3.0)
 		c := a.Add(b)
-		assert.Equal(t, 5.0, c.GetValue())
+		assert.Equal(t, K(5.0), c.GetValue())
 	})
 
 	t.Run("multiplication", func(t *testing.T) {
-		a := NewValue(2.0)
-		b := NewValue(3.0)
+		a := NewValue[K](2.0)
+		b := NewValue[K](3.0)
 		c := a.Mul(b)
-		assert.Equal(t, 6.0, c.GetValue())
+		assert.Equal(t, K(6.0), c.GetValue())
 	})
 
 	t.Run("subtraction", func(t *testing.T) {
-		a := NewValue(5.0)
-		b := NewValue(3.0)
+		a := NewValue[K](5.0)
+		b := NewValue[K](3.0)
 		c := a.Sub(b)
-		assert.Equal(t, 2.0, c.GetValue())
+		assert.Equal(t, K(2.0), c.GetValue())
+	})
+
+	t.Run("division", func(t *testing.T) {
+		a := NewValue[K](6.0)
+		b := NewValue[K](4.0)
+		c := a.Div(b)
+		assert.Equal(t, K(1.5), c.GetValue())
+	})
+
+	t.Run("power", func(t *testing.T) {
+		a := NewValue[K](3.0)
+		assert.Equal(t, K(9.0), a.Pow(2).GetValue())
+		assert.Equal(t, K(27.0), a.PowValue(NewValue[K](3.0)).GetValue())
+	})
+
+	t.Run("negation", func(t *testing.T) {
+		a := NewValue[K](3.0)
+		assert.Equal(t, K(-3.0), a.Neg().GetValue())
 	})
 }
 
 func TestValue_Backpropagation(t *testing.T) {
+	t.Run("float32", testValueBackpropagation[float32])
+	t.Run("float64", testValueBackpropagation[float64])
+}
+
+func testValueBackpropagation[K BaseNumeric](t *testing.T) {
 	// Test case: f(a,b) = a * b + b
 	// df/da = b
 	// df/db = a + 1
-	a := NewValue(2.0, WithName("a"))
-	b := NewValue(3.0, WithName("b"))
+	a := NewValue[K](2.0, WithName("a"))
+	b := NewValue[K](3.0, WithName("b"))
 
 	c := a.Mul(b).SetName("c") // c = a * b
 	d := c.Add(b).SetName("d") // d = c + b = (a * b) + b
@@ -69,23 +112,516 @@ func TestValue_Backpropagation(t *testing.T) {
 	d.SetGradient(1.0)
 	d.Backtrack()
 
-	assert.Equal(t, 3.0, a.GetGradient()) // df/da = b = 3
-	assert.Equal(t, 3.0, b.GetGradient()) // df/db = a + 1 = 3
+	assert.Equal(t, K(3.0), a.GetGradient()) // df/da = b = 3
+	assert.Equal(t, K(3.0), b.GetGradient()) // df/db = a + 1 = 3
+
+	// a second pass adds its own contribution to the leaves only, while c
+	// holds the gradient of the latest pass
+	d.SetGradient(1.0)
+	d.Backtrack()
+
+	assert.Equal(t, K(6.0), a.GetGradient())
+	assert.Equal(t, K(6.0), b.GetGradient())
+	assert.Equal(t, K(1.0), c.GetGradient())
+}
+
+func TestValue_ArithmeticGradients(t *testing.T) {
+	t.Run("float32", testValueArithmeticGradients[float32])
+	t.Run("float64", testValueArithmeticGradients[float64])
+}
+
+func testValueArithmeticGradients[K BaseNumeric](t *testing.T) {
+	t.Run("subtraction", func(t *testing.T) {
+		a := NewValue[K](5.0)
+		b := NewValue[K](3.0)
+		c := a.Sub(b).(*Value[K])
+
+		c.Backward()
+
+		assert.Equal(t, K(1.0), a.GetGradient())
+		assert.Equal(t, K(-1.0), b.GetGradient())
+	})
+
+	t.Run("subtraction beneath other operations", func(t *testing.T) {
+		// f = (a - b) * c
+		a := NewValue[K](5.0)
+		b := NewValue[K](3.0)
+		c := NewValue[K](4.0)
+		f := a.Sub(b).Mul(c).(*Value[K])
+
+		f.Backward()
+
+		assert.Equal(t, K(4.0), a.GetGradient())
+		assert.Equal(t, K(-4.0), b.GetGradient())
+		assert.Equal(t, K(2.0), c.GetGradient())
+	})
+
+	t.Run("division", func(t *testing.T) {
+		// f = a / b
+		// df/da = 1/b, df/db = -a/b^2
+		a := NewValue[K](6.0)
+		b := NewValue[K](4.0)
+		f := a.Div(b).(*Value[K])
+
+		f.Backward()
+
+		assert.Equal(t, K(0.25), a.GetGradient())
+		assert.Equal(t, K(-0.375), b.GetGradient())
+	})
+
+	t.Run("constant power", func(t *testing.T) {
+		// f = a^3, df/da = 3a^2
+		a := NewValue[K](2.0)
+		f := a.Pow(3).(*Value[K])
+
+		f.Backward()
+
+		assert.Equal(t, K(12.0), a.GetGradient())
+	})
+
+	t.Run("value power", func(t *testing.T) {
+		// f = a^b
+		// df/da = b*a^(b-1), df/db = a^b*ln(a)
+		a := NewValue[K](2.0)
+		b := NewValue[K](3.0)
+		f := a.PowValue(b).(*Value[K])
+
+		f.Backward()
+
+		assert.Equal(t, K(12.0), a.GetGradient())
+		assert.InDelta(t, 8*math.Log(2), b.GetGradient(), tolerance[K]())
+	})
+
+	t.Run("power of a negative base", func(t *testing.T) {
+		a := NewValue[K](-2.0)
+		b := NewValue[K](2.0)
+		f := a.PowValue(b).(*Value[K])
+
+		f.Backward()
+
+		assert.Equal(t, K(-4.0), a.GetGradient())
+		assert.Equal(t, K(0.0), b.GetGradient())
+	})
+
+	t.Run("negation", func(t *testing.T) {
+		a := NewValue[K](3.0)
+		f := a.Neg().(*Value[K])
+
+		f.Backward()
+
+		assert.Equal(t, K(-1.0), a.GetGradient())
+	})
+
+	t.Run("squared error", func(t *testing.T) {
+		// L = (y - yhat)^2
+		// dL/dyhat = -2(y - yhat)
+		y := NewValue[K](1.0, WithName("y"))
+		yhat := NewValue[K](4.0, WithName("yhat"))
+		loss := y.Sub(yhat).Pow(2).(*Value[K])
+
+		loss.Backward()
+
+		assert.Equal(t, K(9.0), loss.GetValue())
+		assert.Equal(t, K(6.0), yhat.GetGradient())
+		assert.Equal(t, K(-6.0), y.GetGradient())
+	})
+}
+
+func TestValue_UnaryOperations(t *testing.T) {
+	t.Run("float32", testValueUnaryOperations[float32])
+	t.Run("float64", testValueUnaryOperations[float64])
+}
+
+func testValueUnaryOperations[K BaseNumeric](t *testing.T) {
+	tests := []struct {
+		name     string
+		input    K
+		apply    func(Numeric[K]) Numeric[K]
+		value    float64
+		gradient float64
+	}{
+		{
+			name:     "tanh",
+			input:    0.5,
+			apply:    Numeric[K].Tanh,
+			value:    math.Tanh(0.5),
+			gradient: 1 - math.Tanh(0.5)*math.Tanh(0.5),
+		},
+		{
+			name:     "relu positive",
+			input:    2.0,
+			apply:    Numeric[K].ReLU,
+			value:    2.0,
+			gradient: 1.0,
+		},
+		{
+			name:     "relu negative",
+			input:    -2.0,
+			apply:    Numeric[K].ReLU,
+			value:    0.0,
+			gradient: 0.0,
+		},
+		{
+			name:     "sigmoid",
+			input:    0.0,
+			apply:    Numeric[K].Sigmoid,
+			value:    0.5,
+			gradient: 0.25,
+		},
+		{
+			name:     "exp",
+			input:    1.0,
+			apply:    Numeric[K].Exp,
+			value:    math.E,
+			gradient: math.E,
+		},
+		{
+			name:     "log",
+			input:    2.0,
+			apply:    Numeric[K].Log,
+			value:    math.Ln2,
+			gradient: 0.5,
+		},
+		{
+			name:     "sqrt",
+			input:    4.0,
+			apply:    Numeric[K].Sqrt,
+			value:    2.0,
+			gradient: 0.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewValue(tt.input)
+			out := tt.apply(a)
+			assert.InDelta(t, tt.value, out.GetValue(), tolerance[K]())
+			assert.Equal(t, []Numeric[K]{a}, out.GetChildren())
+
+			out.Backward()
+			assert.InDelta(t, tt.gradient, a.GetGradient(), tolerance[K]())
+		})
+	}
+}
+
+func TestValue_Neuron(t *testing.T) {
+	t.Run("float32", testValueNeuron[float32])
+	t.Run("float64", testValueNeuron[float64])
+}
+
+func testValueNeuron[K BaseNumeric](t *testing.T) {
+	// o = tanh(x1*w1 + x2*w2 + b)
+	x1 := NewValue[K](2.0, WithName("x1"))
+	x2 := NewValue[K](0.0, WithName("x2"))
+	w1 := NewValue[K](-3.0, WithName("w1"))
+	w2 := NewValue[K](1.0, WithName("w2"))
+	b := NewValue[K](6.8813735870195432, WithName("b"))
+
+	n := x1.Mul(w1).Add(x2.Mul(w2)).Add(b).SetName("n")
+	o := n.Tanh().(*Value[K])
+
+	o.Backward()
+
+	assert.InDelta(t, 0.7071, o.GetValue(), 1e-4)
+	assert.InDelta(t, 0.5, n.GetGradient(), 1e-6)
+	assert.InDelta(t, -1.5, x1.GetGradient(), 1e-6)
+	assert.InDelta(t, 1.0, w1.GetGradient(), 1e-6)
+	assert.InDelta(t, 0.5, x2.GetGradient(), 1e-6)
+	assert.InDelta(t, 0.0, w2.GetGradient(), 1e-6)
+}
+
+func TestValue_NaryOperations(t *testing.T) {
+	t.Run("float32", testValueNaryOperations[float32])
+	t.Run("float64", testValueNaryOperations[float64])
+}
+
+func testValueNaryOperations[K BaseNumeric](t *testing.T) {
+	t.Run("sum", func(t *testing.T) {
+		a := NewValue[K](1.0)
+		b := NewValue[K](2.0)
+		c := NewValue[K](3.0)
+		out := Sum[K](a, b, c)
+		assert.Equal(t, K(6.0), out.GetValue())
+		assert.Len(t, out.GetChildren(), 3)
+
+		out.Backward()
+		assert.Equal(t, K(1.0), a.GetGradient())
+		assert.Equal(t, K(1.0), b.GetGradient())
+		assert.Equal(t, K(1.0), c.GetGradient())
+	})
+
+	t.Run("sum of repeated operand", func(t *testing.T) {
+		a := NewValue[K](2.0)
+		out := Sum[K](a, a, a)
+		assert.Equal(t, K(6.0), out.GetValue())
+
+		out.Backward()
+		assert.Equal(t, K(3.0), a.GetGradient())
+	})
+
+	t.Run("empty sum", func(t *testing.T) {
+		out := Sum[K]()
+		assert.Equal(t, K(0.0), out.GetValue())
+		assert.Empty(t, out.GetChildren())
+	})
+
+	t.Run("product", func(t *testing.T) {
+		a := NewValue[K](2.0)
+		b := NewValue[K](3.0)
+		c := NewValue[K](4.0)
+		out := Prod[K](a, b, c)
+		assert.Equal(t, K(24.0), out.GetValue())
+
+		out.Backward()
+		assert.Equal(t, K(12.0), a.GetGradient())
+		assert.Equal(t, K(8.0), b.GetGradient())
+		assert.Equal(t, K(6.0), c.GetGradient())
+	})
+
+	t.Run("product with a zero factor", func(t *testing.T) {
+		a := NewValue[K](0.0)
+		b := NewValue[K](3.0)
+		c := NewValue[K](4.0)
+		out := Prod[K](a, b, c)
+		assert.Equal(t, K(0.0), out.GetValue())
+
+		out.Backward()
+		assert.Equal(t, K(12.0), a.GetGradient())
+		assert.Equal(t, K(0.0), b.GetGradient())
+		assert.Equal(t, K(0.0), c.GetGradient())
+	})
+
+	t.Run("dot product", func(t *testing.T) {
+		w0, w1 := NewValue[K](1.0), NewValue[K](-2.0)
+		x0, x1 := NewValue[K](3.0), NewValue[K](4.0)
+		out := Dot([]Numeric[K]{w0, w1}, []Numeric[K]{x0, x1})
+		assert.Equal(t, K(-5.0), out.GetValue())
+		assert.Len(t, out.GetChildren(), 4)
+
+		out.Backward()
+		assert.Equal(t, K(3.0), w0.GetGradient())
+		assert.Equal(t, K(4.0), w1.GetGradient())
+		assert.Equal(t, K(1.0), x0.GetGradient())
+		assert.Equal(t, K(-2.0), x1.GetGradient())
+	})
+
+	t.Run("dot product of mismatched lengths", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Dot([]Numeric[K]{NewValue[K](1.0)}, []Numeric[K]{})
+		})
+	})
+
+	t.Run("wide neuron", func(t *testing.T) {
+		const n = 100
+		ws := make([]Numeric[K], n)
+		xs := make([]Numeric[K], n)
+		for i := range ws {
+			ws[i] = NewValue(K(0.01) * K(i))
+			xs[i] = NewValue[K](1.0)
+		}
+		out := Dot(ws, xs).Tanh()
+
+		out.Backward()
+		assert.Len(t, TopologicalOrder(out), 2*n+2)
+		slope := 1 - out.GetValue()*out.GetValue()
+		for i := range ws {
+			assert.InDelta(t, slope, ws[i].GetGradient(), tolerance[K]())
+			assert.InDelta(t, slope*K(0.01)*K(i), xs[i].GetGradient(), tolerance[K]())
+		}
+	})
+}
+
+func TestValue_Forward(t *testing.T) {
+	t.Run("float32", testValueForward[float32])
+	t.Run("float64", testValueForward[float64])
+}
+
+func testValueForward[K BaseNumeric](t *testing.T) {
+	t.Run("recomputes after SetValue", func(t *testing.T) {
+		a := NewValue[K](2.0, WithName("a"))
+		b := NewValue[K](3.0, WithName("b"))
+		c := a.Mul(b).SetName("c")
+		d := c.Add(b).SetName("d")
+
+		a.SetValue(4.0)
+		assert.Equal(t, K(9.0), d.GetValue(), "stale until recomputed")
+
+		assert.Equal(t, K(15.0), d.Forward())
+		assert.Equal(t, K(12.0), c.GetValue())
+		assert.Equal(t, K(15.0), d.GetValue())
+	})
+
+	t.Run("every operation", func(t *testing.T) {
+		build := func(a, b *Value[K]) Numeric[K] {
+			return Sum[K](
+				a.Add(b), a.Sub(b), a.Mul(b), a.Div(b), a.Pow(2), a.PowValue(b), a.Neg(),
+				a.Tanh(), b.ReLU(), a.Sigmoid(), a.Exp(), b.Log(), b.Sqrt(),
+				Prod[K](a, b, a), Dot([]Numeric[K]{a, b}, []Numeric[K]{b, a}),
+			)
+		}
+		a, b := NewValue[K](0.5), NewValue[K](1.5)
+		out := build(a, b)
+
+		a.SetValue(-0.25)
+		b.SetValue(2.5)
+		want := build(NewValue[K](-0.25), NewValue[K](2.5)).GetValue()
+
+		assert.InDelta(t, want, out.Forward(), tolerance[K]())
+	})
+
+	t.Run("graph reused across samples", func(t *testing.T) {
+		// fit y = 2x + 1 with a single graph built once
+		x := NewValue[K](0, WithName("x"))
+		y := NewValue[K](0, WithName("y"))
+		w := NewValue[K](0, WithName("w"))
+		b := NewValue[K](0, WithName("b"))
+		loss := w.Mul(x).Add(b).Sub(y).Pow(2).(*Value[K])
+
+		for epoch := 0; epoch < 200; epoch++ {
+			for _, sample := range [][2]K{{-1, -1}, {0, 1}, {1, 3}, {2, 5}} {
+				x.SetValue(sample[0])
+				y.SetValue(sample[1])
+				loss.Forward()
+
+				ZeroGrad[K](loss)
+				loss.Backward()
+				w.SetValue(w.GetValue() - 0.05*w.GetGradient())
+				b.SetValue(b.GetValue() - 0.05*b.GetGradient())
+			}
+		}
+
+		assert.InDelta(t, 2.0, w.GetValue(), 1e-3)
+		assert.InDelta(t, 1.0, b.GetValue(), 1e-3)
+	})
+}
+
+func TestOperationEnum_String(t *testing.T) {
+	assert.Equal(t, "+", OperationEnum(ADD).String())
+	assert.Equal(t, "^", OperationEnum(POW).String())
+	assert.Equal(t, "tanh", OperationEnum(TANH).String())
+	assert.Equal(t, "", OperationEnum(UNSET).String())
 }
 
 func TestValue_Options(t *testing.T) {
+	t.Run("float32", testValueOptions[float32])
+	t.Run("float64", testValueOptions[float64])
+}
+
+func testValueOptions[K BaseNumeric](t *testing.T) {
 	t.Run("with name", func(t *testing.T) {
-		v := NewValue(2.0, WithName("test"))
+		v := NewValue[K](2.0, WithName("test"))
 		assert.Equal(t, "test", v.GetName())
 	})
 
 	t.Run("with gradient", func(t *testing.T) {
-		v := NewValue(2.0, WithGradient(1.0))
-		assert.Equal(t, 1.0, v.GetGradient())
+		v := NewValue[K](2.0, WithGradient(1.0))
+		assert.Equal(t, K(1.0), v.GetGradient())
 	})
 
 	t.Run("with value", func(t *testing.T) {
-		v := NewValue(2.0, WithValue(3.0))
-		assert.Equal(t, 3.0, v.GetValue())
+		v := NewValue[K](2.0, WithValue(3.0))
+		assert.Equal(t, K(3.0), v.GetValue())
+	})
+
+	t.Run("with typed option arguments", func(t *testing.T) {
+		v := NewValue[K](2.0, WithGradient(K(0.1)), WithValue(K(0.3)))
+		assert.Equal(t, K(0.1), v.GetGradient())
+		assert.Equal(t, K(0.3), v.GetValue())
+	})
+
+	t.Run("with requires grad", func(t *testing.T) {
+		assert.True(t, NewValue[K](2.0).RequiresGrad())
+		assert.False(t, NewValue[K](2.0, WithRequiresGrad(false)).RequiresGrad())
+	})
+}
+
+func TestValue_RequiresGrad(t *testing.T) {
+	t.Run("float32", testValueRequiresGrad[float32])
+	t.Run("float64", testValueRequiresGrad[float64])
+}
+
+func testValueRequiresGrad[K BaseNumeric](t *testing.T) {
+	t.Run("constants get no gradient", func(t *testing.T) {
+		// L = (a*b + c) * f with f constant
+		a := NewValue[K](2.0)
+		b := NewValue[K](-3.0)
+		c := NewValue[K](10.0)
+		f := NewValue[K](-2.0, WithRequiresGrad(false))
+		L := a.Mul(b).Add(c).Mul(f)
+
+		L.Backward()
+
+		assert.Equal(t, K(6.0), a.GetGradient())
+		assert.Equal(t, K(-4.0), b.GetGradient())
+		assert.Equal(t, K(-2.0), c.GetGradient())
+		assert.Equal(t, K(0), f.GetGradient())
+	})
+
+	t.Run("interior nodes inherit the flag", func(t *testing.T) {
+		x := NewValue[K](1.0)
+		k := NewValue[K](2.0, WithRequiresGrad(false))
+
+		assert.False(t, k.Mul(k).Exp().RequiresGrad())
+		assert.True(t, k.Mul(x).RequiresGrad())
+		assert.False(t, Sum[K]().RequiresGrad())
+		assert.False(t, x.Pow(2).GetChildren()[1].RequiresGrad())
+	})
+
+	t.Run("frozen subgraphs are skipped", func(t *testing.T) {
+		w := NewValue[K](0.5)
+		frozen := NewValue[K](3.0)
+		h := frozen.Mul(frozen).Tanh().(*Value[K])
+		out := w.Mul(h)
+		frozen.SetRequiresGrad(false)
+
+		out.Backward()
+
+		assert.InDelta(t, float64(h.GetValue()), float64(w.GetGradient()), tolerance[K]())
+		assert.Equal(t, K(0), frozen.GetGradient())
+		assert.False(t, h.RequiresGrad())
+		assert.Equal(t, K(0), h.GetGradient())
+	})
+
+	t.Run("unfreezing after the graph is built", func(t *testing.T) {
+		w := NewValue[K](3.0, WithRequiresGrad(false))
+		k := NewValue[K](2.0, WithRequiresGrad(false))
+		out := w.Mul(k)
+		assert.False(t, out.RequiresGrad())
+
+		w.SetRequiresGrad(true)
+		assert.True(t, out.RequiresGrad())
+		out.Backward()
+
+		assert.Equal(t, K(2.0), w.GetGradient())
+		assert.Equal(t, K(0), k.GetGradient())
+	})
+
+	t.Run("stop gradient on an interior node", func(t *testing.T) {
+		// f = x * stop(x^2), df/dx = x^2
+		x := NewValue[K](3.0)
+		stop := x.Mul(x).(*Value[K]).SetRequiresGrad(false)
+		f := x.Mul(stop)
+
+		f.Backward()
+
+		assert.Equal(t, K(9.0), x.GetGradient())
+		assert.Equal(t, K(0), stop.GetGradient())
+	})
+
+	t.Run("detach", func(t *testing.T) {
+		x := NewValue[K](3.0, WithName("x"))
+		y := x.Mul(x).(*Value[K]).SetName("y")
+
+		d := y.Detach()
+		assert.Equal(t, K(9.0), d.GetValue())
+		assert.Equal(t, "y", d.GetName())
+		assert.Empty(t, d.GetChildren())
+		assert.False(t, d.RequiresGrad())
+		assert.True(t, y.RequiresGrad())
+
+		f := x.Add(d)
+		f.Backward()
+		assert.Equal(t, K(1.0), x.GetGradient())
+		assert.Equal(t, K(0), d.GetGradient())
 	})
 }
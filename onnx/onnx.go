@@ -0,0 +1,357 @@
+// Package onnx converts scalar computation graphs to and from the ONNX model
+// format, so they can be inspected and run with standard ONNX tooling.
+//
+// Every value is exported as a rank-zero tensor. Leaves become initializers,
+// named leaves are additionally listed as graph inputs, and the root is the
+// single graph output.
+package onnx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"microgograd/micrograd"
+)
+
+const (
+	irVersion    = 7
+	opsetVersion = 13
+	producerName = "microgograd"
+)
+
+// opTypes maps the built-in operations to their ONNX operator. SUM, PROD
+// and DOT are handled separately since they may expand to several nodes.
+var opTypes = map[micrograd.OperationEnum]string{
+	micrograd.ADD:     "Add",
+	micrograd.SUB:     "Sub",
+	micrograd.MUL:     "Mul",
+	micrograd.DIV:     "Div",
+	micrograd.POW:     "Pow",
+	micrograd.NEG:     "Neg",
+	micrograd.TANH:    "Tanh",
+	micrograd.RELU:    "Relu",
+	micrograd.SIGMOID: "Sigmoid",
+	micrograd.EXP:     "Exp",
+	micrograd.LOG:     "Log",
+	micrograd.SQRT:    "Sqrt",
+}
+
+// exporter holds the state of a single Export call.
+type exporter[K micrograd.BaseNumeric] struct {
+	graph    graphProto
+	elemType int32
+	names    map[micrograd.Numeric[K]]string
+	used     map[string]bool
+	counter  int
+}
+
+// Export encodes the graph rooted at root as a serialized ONNX ModelProto.
+// float32 graphs use FLOAT tensors and float64 graphs DOUBLE tensors.
+// Gradients are not exported.
+//
+// Leaf names must be unique. Interior nodes keep their name when it is free
+// and get a generated one otherwise. Registered custom operations are not
+// supported.
+func Export[K micrograd.BaseNumeric](root micrograd.Numeric[K]) ([]byte, error) {
+	e := &exporter[K]{
+		graph:    graphProto{name: "microgograd"},
+		elemType: dataTypeDouble,
+		names:    make(map[micrograd.Numeric[K]]string),
+		used:     make(map[string]bool),
+	}
+	if _, ok := any(K(0)).(float32); ok {
+		e.elemType = dataTypeFloat
+	}
+
+	nodes := micrograd.TopologicalOrder(root)
+
+	// Leaf names are claimed first so generated names never shadow them.
+	for _, n := range nodes {
+		if len(n.GetChildren()) > 0 || n.GetName() == "" {
+			continue
+		}
+		if e.used[n.GetName()] {
+			return nil, fmt.Errorf("duplicate leaf name %q", n.GetName())
+		}
+		e.used[n.GetName()] = true
+	}
+
+	for _, n := range nodes {
+		if err := e.export(n); err != nil {
+			return nil, err
+		}
+	}
+	e.graph.outputs = []valueInfoProto{{name: e.names[root], elemType: e.elemType}}
+
+	model := modelProto{
+		irVersion:    irVersion,
+		producerName: producerName,
+		opsetImport:  []opsetID{{version: opsetVersion}},
+		graph:        e.graph,
+	}
+	return model.marshal(), nil
+}
+
+// WriteFile exports the graph rooted at root to the named file.
+func WriteFile[K micrograd.BaseNumeric](name string, root micrograd.Numeric[K]) error {
+	data, err := Export(root)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
+
+func (e *exporter[K]) export(n micrograd.Numeric[K]) error {
+	children := n.GetChildren()
+	if len(children) == 0 {
+		name := n.GetName()
+		if name == "" {
+			e.names[n] = e.constant(n.GetValue())
+			return nil
+		}
+		e.graph.initializer = append(e.graph.initializer, e.tensor(name, n.GetValue()))
+		e.graph.inputs = append(e.graph.inputs, valueInfoProto{name: name, elemType: e.elemType})
+		e.names[n] = name
+		return nil
+	}
+
+	out := n.GetName()
+	if out == "" || e.used[out] {
+		out = e.fresh("t")
+	} else {
+		e.used[out] = true
+	}
+	e.names[n] = out
+
+	inputs := make([]string, len(children))
+	for i, c := range children {
+		inputs[i] = e.names[c]
+	}
+
+	op := n.GetOperation()
+	switch op {
+	case micrograd.SUM:
+		e.sum(inputs, out, n.GetName())
+	case micrograd.PROD:
+		e.product(inputs, out, n.GetName())
+	case micrograd.DOT:
+		half := len(inputs) / 2
+		if half == 1 {
+			e.node("Mul", inputs, out, n.GetName())
+			break
+		}
+		terms := make([]string, half)
+		for i := range terms {
+			terms[i] = e.fresh("t")
+			e.node("Mul", []string{inputs[i], inputs[half+i]}, terms[i], "")
+		}
+		e.sum(terms, out, n.GetName())
+	default:
+		opType, ok := opTypes[op]
+		if !ok {
+			return fmt.Errorf("operation %q cannot be exported to ONNX", op)
+		}
+		e.node(opType, inputs, out, n.GetName())
+	}
+	return nil
+}
+
+// sum emits a Sum node, or Identity of zero when there are no inputs.
+func (e *exporter[K]) sum(inputs []string, out, name string) {
+	if len(inputs) == 0 {
+		e.node("Identity", []string{e.constant(0)}, out, name)
+		return
+	}
+	e.node("Sum", inputs, out, name)
+}
+
+// product emits a chain of binary Mul nodes, since ONNX has no n-ary
+// product.
+func (e *exporter[K]) product(inputs []string, out, name string) {
+	switch len(inputs) {
+	case 0:
+		e.node("Identity", []string{e.constant(1)}, out, name)
+	case 1:
+		e.node("Identity", inputs, out, name)
+	default:
+		acc := inputs[0]
+		for i, in := range inputs[1:] {
+			next := out
+			if i < len(inputs)-2 {
+				next = e.fresh("t")
+			}
+			e.node("Mul", []string{acc, in}, next, "")
+			acc = next
+		}
+		e.graph.nodes[len(e.graph.nodes)-1].name = name
+	}
+}
+
+func (e *exporter[K]) node(opType string, inputs []string, out, name string) {
+	e.graph.nodes = append(e.graph.nodes, nodeProto{
+		inputs:  inputs,
+		outputs: []string{out},
+		name:    name,
+		opType:  opType,
+	})
+}
+
+// constant adds an unnamed initializer holding x and returns its name.
+func (e *exporter[K]) constant(x K) string {
+	name := e.fresh("const_")
+	e.graph.initializer = append(e.graph.initializer, e.tensor(name, x))
+	return name
+}
+
+func (e *exporter[K]) tensor(name string, x K) tensorProto {
+	t := tensorProto{name: name, dataType: e.elemType}
+	if e.elemType == dataTypeFloat {
+		t.floatData = []float32{float32(x)}
+	} else {
+		t.doubleData = []float64{float64(x)}
+	}
+	return t
+}
+
+// fresh returns an unused tensor name with the given prefix.
+func (e *exporter[K]) fresh(prefix string) string {
+	for {
+		name := fmt.Sprintf("%s%d", prefix, e.counter)
+		e.counter++
+		if !e.used[name] {
+			e.used[name] = true
+			return name
+		}
+	}
+}
+
+// Import decodes an ONNX model and rebuilds it as a graph, returning its
+// root. Only the subset written by Export is understood: scalar tensors,
+// a single graph output and the operators Add, Sub, Mul, Div, Pow, Neg,
+// Tanh, Relu, Sigmoid, Exp, Log, Sqrt, Sum and Identity.
+//
+// Graph inputs become named leaves, taking their value from the initializer
+// of the same name or zero if there is none. Remaining initializers become
+// unnamed constants that do not require gradients. Node names are carried over to the values they produce.
+func Import[K micrograd.BaseNumeric](data []byte) (*micrograd.Value[K], error) {
+	model, err := unmarshalModel(data)
+	if err != nil {
+		return nil, fmt.Errorf("onnx: %v", err)
+	}
+	g := &model.graph
+	if len(g.outputs) != 1 {
+		return nil, fmt.Errorf("onnx: graph has %d outputs, want 1", len(g.outputs))
+	}
+
+	inputs := make(map[string]bool, len(g.inputs))
+	for _, in := range g.inputs {
+		inputs[in.name] = true
+	}
+
+	tensors := make(map[string]micrograd.Numeric[K])
+	for _, t := range g.initializer {
+		x, err := t.scalar()
+		if err != nil {
+			return nil, fmt.Errorf("onnx: %v", err)
+		}
+		if inputs[t.name] {
+			tensors[t.name] = micrograd.NewValue(K(x), micrograd.WithName(t.name))
+		} else {
+			tensors[t.name] = micrograd.NewValue(K(x), micrograd.WithRequiresGrad(false))
+		}
+	}
+	for _, in := range g.inputs {
+		if _, ok := tensors[in.name]; !ok {
+			tensors[in.name] = micrograd.NewValue(K(0), micrograd.WithName(in.name))
+		}
+	}
+
+	for i, node := range g.nodes {
+		out, err := importNode(node, tensors)
+		if err != nil {
+			return nil, fmt.Errorf("onnx: node %d (%s): %v", i, node.opType, err)
+		}
+		if node.name != "" {
+			out.SetName(node.name)
+		}
+		tensors[node.outputs[0]] = out
+	}
+
+	root, ok := tensors[g.outputs[0].name]
+	if !ok {
+		return nil, fmt.Errorf("onnx: output %q is never produced", g.outputs[0].name)
+	}
+	return root.(*micrograd.Value[K]), nil
+}
+
+// ReadFile imports the ONNX model stored in the named file.
+func ReadFile[K micrograd.BaseNumeric](name string) (*micrograd.Value[K], error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return Import[K](data)
+}
+
+func importNode[K micrograd.BaseNumeric](node nodeProto, tensors map[string]micrograd.Numeric[K]) (*micrograd.Value[K], error) {
+	if len(node.outputs) != 1 {
+		return nil, fmt.Errorf("%d outputs, want 1", len(node.outputs))
+	}
+	in := make([]micrograd.Numeric[K], len(node.inputs))
+	for i, name := range node.inputs {
+		t, ok := tensors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown input %q", name)
+		}
+		in[i] = t
+	}
+
+	want := 1
+	switch node.opType {
+	case "Add", "Sub", "Mul", "Div", "Pow":
+		want = 2
+	case "Sum":
+		if len(in) == 0 {
+			return nil, errors.New("no inputs")
+		}
+		want = len(in)
+	}
+	if len(in) != want {
+		return nil, fmt.Errorf("%d inputs, want %d", len(in), want)
+	}
+
+	var out micrograd.Numeric[K]
+	switch node.opType {
+	case "Add":
+		out = in[0].Add(in[1])
+	case "Sub":
+		out = in[0].Sub(in[1])
+	case "Mul":
+		out = in[0].Mul(in[1])
+	case "Div":
+		out = in[0].Div(in[1])
+	case "Pow":
+		out = in[0].PowValue(in[1])
+	case "Neg":
+		out = in[0].Neg()
+	case "Tanh":
+		out = in[0].Tanh()
+	case "Relu":
+		out = in[0].ReLU()
+	case "Sigmoid":
+		out = in[0].Sigmoid()
+	case "Exp":
+		out = in[0].Exp()
+	case "Log":
+		out = in[0].Log()
+	case "Sqrt":
+		out = in[0].Sqrt()
+	case "Sum", "Identity":
+		// a single-input sum passes value and gradient through unchanged
+		out = micrograd.Sum(in...)
+	default:
+		return nil, errors.New("unsupported operator")
+	}
+	return out.(*micrograd.Value[K]), nil
+}
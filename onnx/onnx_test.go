@@ -0,0 +1,245 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"microgograd/micrograd"
+)
+
+// leafGradients maps the names of the leaves under root to their gradients.
+func leafGradients[K micrograd.BaseNumeric](root micrograd.Numeric[K]) map[string]K {
+	grads := make(map[string]K)
+	for _, n := range micrograd.TopologicalOrder(root) {
+		if len(n.GetChildren()) == 0 && n.GetName() != "" {
+			grads[n.GetName()] = n.GetGradient()
+		}
+	}
+	return grads
+}
+
+type cube struct{}
+
+func (cube) Name() string   { return "onnx_test.cube" }
+func (cube) Symbol() string { return "³" }
+func (cube) Arity() int     { return 1 }
+
+func (cube) Forward(inputs []float64) float64 {
+	return inputs[0] * inputs[0] * inputs[0]
+}
+
+func (cube) Gradients(inputs []float64, _ float64) []float64 {
+	return []float64{3 * inputs[0] * inputs[0]}
+}
+
+func TestExport(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		a := micrograd.NewValue(0.7, micrograd.WithName("a"))
+		b := micrograd.NewValue(1.3, micrograd.WithName("b"))
+		c := micrograd.NewValue(-0.4, micrograd.WithName("c"))
+		y := a.Mul(b).Sigmoid().SetName("y")
+		out := micrograd.Sum[float64](
+			y.Mul(y), y.Div(b), c.Tanh().Pow(2), micrograd.Prod[float64](a, b, c).Exp(),
+			a.PowValue(b), b.Sqrt().Log(), c.Neg().ReLU(), a.Sub(c), a.Add(c),
+			micrograd.Dot([]micrograd.Numeric[float64]{a, b}, []micrograd.Numeric[float64]{c, c}),
+			micrograd.Dot([]micrograd.Numeric[float64]{a}, []micrograd.Numeric[float64]{b}),
+			micrograd.Prod[float64](c), micrograd.Sum[float64](),
+		).SetName("out")
+		out.Backward()
+
+		data, err := Export[float64](out)
+		assert.NoError(t, err)
+
+		loaded, err := Import[float64](data)
+		assert.NoError(t, err)
+		assert.Equal(t, "out", loaded.GetName())
+		assert.InDelta(t, out.GetValue(), loaded.GetValue(), 1e-12)
+
+		loaded.Backward()
+		want := leafGradients[float64](out)
+		got := leafGradients[float64](loaded)
+		assert.Len(t, got, 3)
+		for name, grad := range want {
+			assert.InDelta(t, grad, got[name], 1e-12, name)
+		}
+	})
+
+	t.Run("float32", func(t *testing.T) {
+		x := micrograd.NewValue[float32](0.5, micrograd.WithName("x"))
+		w := micrograd.NewValue[float32](-1.5, micrograd.WithName("w"))
+		out := x.Mul(w).Add(micrograd.NewValue[float32](0.25)).Tanh().SetName("out")
+		out.Backward()
+
+		data, err := Export[float32](out)
+		assert.NoError(t, err)
+
+		model, err := unmarshalModel(data)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(dataTypeFloat), model.graph.outputs[0].elemType)
+
+		loaded, err := Import[float32](data)
+		assert.NoError(t, err)
+		assert.Equal(t, out.GetValue(), loaded.GetValue())
+
+		loaded.Backward()
+		assert.Equal(t, leafGradients[float32](out), leafGradients[float32](loaded))
+	})
+
+	t.Run("graph layout", func(t *testing.T) {
+		x := micrograd.NewValue(2.0, micrograd.WithName("x"))
+		h := x.Mul(micrograd.NewValue(3.0)).SetName("h")
+		out := h.Add(h).ReLU().SetName("x")
+
+		data, err := Export[float64](out)
+		assert.NoError(t, err)
+
+		model, err := unmarshalModel(data)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(irVersion), model.irVersion)
+		assert.Equal(t, []opsetID{{version: opsetVersion}}, model.opsetImport)
+
+		g := model.graph
+		assert.Equal(t, []valueInfoProto{{name: "x", elemType: dataTypeDouble}}, g.inputs)
+		assert.Len(t, g.initializer, 2)
+		assert.Equal(t, "x", g.initializer[0].name)
+		assert.Equal(t, "const_0", g.initializer[1].name)
+
+		// the root's name is taken by the input, so its tensor is renamed
+		// while the node keeps the name
+		assert.Equal(t, []nodeProto{
+			{inputs: []string{"x", "const_0"}, outputs: []string{"h"}, name: "h", opType: "Mul"},
+			{inputs: []string{"h", "h"}, outputs: []string{"t1"}, opType: "Add"},
+			{inputs: []string{"t1"}, outputs: []string{"t2"}, name: "x", opType: "Relu"},
+		}, g.nodes)
+		assert.Equal(t, []valueInfoProto{{name: "t2", elemType: dataTypeDouble}}, g.outputs)
+	})
+
+	t.Run("duplicate leaf names", func(t *testing.T) {
+		a := micrograd.NewValue(1.0, micrograd.WithName("a"))
+		b := micrograd.NewValue(2.0, micrograd.WithName("a"))
+
+		_, err := Export[float64](a.Add(b))
+		assert.EqualError(t, err, `duplicate leaf name "a"`)
+	})
+
+	t.Run("custom operations", func(t *testing.T) {
+		micrograd.MustRegisterOp[float64](cube{})
+		x := micrograd.NewValue(2.0, micrograd.WithName("x"))
+
+		_, err := Export[float64](micrograd.Apply[float64](cube{}, x))
+		assert.EqualError(t, err, `operation "³" cannot be exported to ONNX`)
+	})
+}
+
+func TestImport(t *testing.T) {
+	scalar := func(name string, x float64) tensorProto {
+		return tensorProto{name: name, dataType: dataTypeDouble, doubleData: []float64{x}}
+	}
+	model := func(g graphProto) []byte {
+		m := modelProto{irVersion: irVersion, opsetImport: []opsetID{{version: opsetVersion}}, graph: g}
+		return m.marshal()
+	}
+
+	t.Run("inputs and initializers", func(t *testing.T) {
+		raw := make([]byte, 4)
+		binary.LittleEndian.PutUint32(raw, math.Float32bits(1.5))
+
+		data := model(graphProto{
+			nodes: []nodeProto{
+				{inputs: []string{"x", "k"}, outputs: []string{"y"}, opType: "Mul"},
+				{inputs: []string{"y", "b"}, outputs: []string{"z"}, name: "z", opType: "Add"},
+			},
+			initializer: []tensorProto{{name: "k", dims: []int64{1}, dataType: dataTypeFloat, rawData: raw}, scalar("b", 4)},
+			inputs:      []valueInfoProto{{name: "x"}, {name: "b"}},
+			outputs:     []valueInfoProto{{name: "z"}},
+		})
+
+		z, err := Import[float64](data)
+		assert.NoError(t, err)
+		assert.Equal(t, "z", z.GetName())
+		assert.Equal(t, 4.0, z.GetValue())
+
+		mul, b := z.GetChildren()[0], z.GetChildren()[1]
+		assert.Equal(t, "", mul.GetName())
+		assert.Equal(t, "b", b.GetName())
+		assert.Equal(t, "x", mul.GetChildren()[0].GetName())
+		assert.Equal(t, "", mul.GetChildren()[1].GetName())
+		assert.Equal(t, 1.5, mul.GetChildren()[1].GetValue())
+		assert.False(t, mul.GetChildren()[1].RequiresGrad())
+		assert.True(t, b.RequiresGrad())
+	})
+
+	t.Run("constants stay constant", func(t *testing.T) {
+		a := micrograd.NewValue(3.0, micrograd.WithName("a"))
+		data, err := Export[float64](a.Pow(2))
+		assert.NoError(t, err)
+		root, err := Import[float64](data)
+		assert.NoError(t, err)
+
+		root.Backward()
+		base, exponent := root.GetChildren()[0], root.GetChildren()[1]
+		assert.Equal(t, 6.0, base.GetGradient())
+		assert.Equal(t, 0.0, exponent.GetGradient())
+
+		data, err = Export[float64](a.Pow(1))
+		assert.NoError(t, err)
+		root, err = Import[float64](data)
+		assert.NoError(t, err)
+		assert.Equal(t, "a", micrograd.Simplify[float64](root).GetName(), "x^1 simplifies to x")
+	})
+
+	t.Run("files", func(t *testing.T) {
+		x := micrograd.NewValue(0.3, micrograd.WithName("x"))
+		out := x.Exp().Sub(x).SetName("out")
+		path := filepath.Join(t.TempDir(), "model.onnx")
+
+		assert.NoError(t, WriteFile[float64](path, out))
+		loaded, err := ReadFile[float64](path)
+		assert.NoError(t, err)
+		assert.Equal(t, out.GetValue(), loaded.GetValue())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			name string
+			data []byte
+			err  string
+		}{
+			{"malformed", []byte{0xff}, "onnx: unexpected EOF"},
+			{"no graph", protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), irVersion), "onnx: model has no graph"},
+			{"outputs", model(graphProto{}), "onnx: graph has 0 outputs, want 1"},
+			{"operator", model(graphProto{
+				nodes:   []nodeProto{{inputs: []string{"x"}, outputs: []string{"y"}, opType: "Softmax"}},
+				inputs:  []valueInfoProto{{name: "x"}},
+				outputs: []valueInfoProto{{name: "y"}},
+			}), "onnx: node 0 (Softmax): unsupported operator"},
+			{"arity", model(graphProto{
+				nodes:   []nodeProto{{inputs: []string{"x"}, outputs: []string{"y"}, opType: "Add"}},
+				inputs:  []valueInfoProto{{name: "x"}},
+				outputs: []valueInfoProto{{name: "y"}},
+			}), "onnx: node 0 (Add): 1 inputs, want 2"},
+			{"unknown input", model(graphProto{
+				nodes:   []nodeProto{{inputs: []string{"x"}, outputs: []string{"y"}, opType: "Neg"}},
+				outputs: []valueInfoProto{{name: "y"}},
+			}), `onnx: node 0 (Neg): unknown input "x"`},
+			{"missing output", model(graphProto{
+				inputs:  []valueInfoProto{{name: "x"}},
+				outputs: []valueInfoProto{{name: "y"}},
+			}), `onnx: output "y" is never produced`},
+			{"tensor shape", model(graphProto{
+				initializer: []tensorProto{{name: "x", dims: []int64{2}, dataType: dataTypeDouble, doubleData: []float64{1, 2}}},
+				outputs:     []valueInfoProto{{name: "x"}},
+			}), `onnx: tensor "x" is not a scalar`},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := Import[float64](tc.data)
+				assert.EqualError(t, err, tc.err)
+			})
+		}
+	})
+}
@@ -0,0 +1,443 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The messages below mirror the subset of onnx.proto needed for scalar
+// graphs. They are encoded by hand with protowire, so no generated code is
+// required; field numbers follow the upstream schema.
+
+// TensorProto.DataType values.
+const (
+	dataTypeFloat  = 1
+	dataTypeDouble = 11
+)
+
+type modelProto struct {
+	irVersion    int64
+	producerName string
+	opsetImport  []opsetID
+	graph        graphProto
+}
+
+type opsetID struct {
+	domain  string
+	version int64
+}
+
+type graphProto struct {
+	name        string
+	nodes       []nodeProto
+	initializer []tensorProto
+	inputs      []valueInfoProto
+	outputs     []valueInfoProto
+}
+
+type nodeProto struct {
+	inputs  []string
+	outputs []string
+	name    string
+	opType  string
+}
+
+type tensorProto struct {
+	name       string
+	dims       []int64
+	dataType   int32
+	floatData  []float32
+	doubleData []float64
+	rawData    []byte
+}
+
+type valueInfoProto struct {
+	name     string
+	elemType int32
+}
+
+func (m *modelProto) marshal() []byte {
+	var b []byte
+	b = appendVarint(b, 1, uint64(m.irVersion))
+	b = appendString(b, 2, m.producerName)
+	b = appendMessage(b, 7, m.graph.marshal())
+	for _, op := range m.opsetImport {
+		var ob []byte
+		ob = appendString(ob, 1, op.domain)
+		ob = appendVarint(ob, 2, uint64(op.version))
+		b = appendMessage(b, 8, ob)
+	}
+	return b
+}
+
+func (g *graphProto) marshal() []byte {
+	var b []byte
+	for _, n := range g.nodes {
+		b = appendMessage(b, 1, n.marshal())
+	}
+	b = appendString(b, 2, g.name)
+	for _, t := range g.initializer {
+		b = appendMessage(b, 5, t.marshal())
+	}
+	for _, v := range g.inputs {
+		b = appendMessage(b, 11, v.marshal())
+	}
+	for _, v := range g.outputs {
+		b = appendMessage(b, 12, v.marshal())
+	}
+	return b
+}
+
+func (n *nodeProto) marshal() []byte {
+	var b []byte
+	for _, in := range n.inputs {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, in)
+	}
+	for _, out := range n.outputs {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendString(b, out)
+	}
+	b = appendString(b, 3, n.name)
+	b = appendString(b, 4, n.opType)
+	return b
+}
+
+func (t *tensorProto) marshal() []byte {
+	var b []byte
+	if len(t.dims) > 0 {
+		var packed []byte
+		for _, d := range t.dims {
+			packed = protowire.AppendVarint(packed, uint64(d))
+		}
+		b = appendMessage(b, 1, packed)
+	}
+	b = appendVarint(b, 2, uint64(t.dataType))
+	if len(t.floatData) > 0 {
+		var packed []byte
+		for _, x := range t.floatData {
+			packed = protowire.AppendFixed32(packed, math.Float32bits(x))
+		}
+		b = appendMessage(b, 4, packed)
+	}
+	b = appendString(b, 8, t.name)
+	if len(t.rawData) > 0 {
+		b = appendMessage(b, 9, t.rawData)
+	}
+	if len(t.doubleData) > 0 {
+		var packed []byte
+		for _, x := range t.doubleData {
+			packed = protowire.AppendFixed64(packed, math.Float64bits(x))
+		}
+		b = appendMessage(b, 10, packed)
+	}
+	return b
+}
+
+// marshal encodes a scalar tensor type: TypeProto.tensor_type with an empty
+// shape, meaning rank zero.
+func (v *valueInfoProto) marshal() []byte {
+	var tensor []byte
+	tensor = appendVarint(tensor, 1, uint64(v.elemType))
+	tensor = protowire.AppendTag(tensor, 2, protowire.BytesType)
+	tensor = protowire.AppendBytes(tensor, nil)
+
+	var b []byte
+	b = appendString(b, 1, v.name)
+	b = appendMessage(b, 2, appendMessage(nil, 1, tensor))
+	return b
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendMessage(b []byte, num protowire.Number, m []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, m)
+}
+
+// field is a single decoded key/value pair of a protobuf message.
+type field struct {
+	num    protowire.Number
+	typ    protowire.Type
+	varint uint64
+	fixed  uint64
+	bytes  []byte
+}
+
+func decodeFields(b []byte) ([]field, error) {
+	var fields []field
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		f := field{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.fixed = uint64(v)
+		case protowire.Fixed64Type:
+			f.fixed, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func unmarshalModel(b []byte) (*modelProto, error) {
+	fields, err := decodeFields(b)
+	if err != nil {
+		return nil, err
+	}
+	m := &modelProto{}
+	hasGraph := false
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			m.irVersion = int64(f.varint)
+		case 2:
+			m.producerName = string(f.bytes)
+		case 7:
+			if err := m.graph.unmarshal(f.bytes); err != nil {
+				return nil, fmt.Errorf("graph: %v", err)
+			}
+			hasGraph = true
+		case 8:
+			op, err := unmarshalOpset(f.bytes)
+			if err != nil {
+				return nil, fmt.Errorf("opset_import: %v", err)
+			}
+			m.opsetImport = append(m.opsetImport, op)
+		}
+	}
+	if !hasGraph {
+		return nil, errors.New("model has no graph")
+	}
+	return m, nil
+}
+
+func unmarshalOpset(b []byte) (opsetID, error) {
+	fields, err := decodeFields(b)
+	if err != nil {
+		return opsetID{}, err
+	}
+	var op opsetID
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			op.domain = string(f.bytes)
+		case 2:
+			op.version = int64(f.varint)
+		}
+	}
+	return op, nil
+}
+
+func (g *graphProto) unmarshal(b []byte) error {
+	fields, err := decodeFields(b)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			n, err := unmarshalNode(f.bytes)
+			if err != nil {
+				return fmt.Errorf("node: %v", err)
+			}
+			g.nodes = append(g.nodes, n)
+		case 2:
+			g.name = string(f.bytes)
+		case 5:
+			t, err := unmarshalTensor(f.bytes)
+			if err != nil {
+				return fmt.Errorf("initializer: %v", err)
+			}
+			g.initializer = append(g.initializer, t)
+		case 11, 12:
+			v, err := unmarshalValueInfo(f.bytes)
+			if err != nil {
+				return fmt.Errorf("value info: %v", err)
+			}
+			if f.num == 11 {
+				g.inputs = append(g.inputs, v)
+			} else {
+				g.outputs = append(g.outputs, v)
+			}
+		}
+	}
+	return nil
+}
+
+func unmarshalNode(b []byte) (nodeProto, error) {
+	fields, err := decodeFields(b)
+	if err != nil {
+		return nodeProto{}, err
+	}
+	var n nodeProto
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			n.inputs = append(n.inputs, string(f.bytes))
+		case 2:
+			n.outputs = append(n.outputs, string(f.bytes))
+		case 3:
+			n.name = string(f.bytes)
+		case 4:
+			n.opType = string(f.bytes)
+		}
+	}
+	return n, nil
+}
+
+func unmarshalTensor(b []byte) (tensorProto, error) {
+	fields, err := decodeFields(b)
+	if err != nil {
+		return tensorProto{}, err
+	}
+	var t tensorProto
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			if f.typ == protowire.BytesType {
+				for rest := f.bytes; len(rest) > 0; {
+					v, n := protowire.ConsumeVarint(rest)
+					if n < 0 {
+						return t, protowire.ParseError(n)
+					}
+					t.dims = append(t.dims, int64(v))
+					rest = rest[n:]
+				}
+			} else {
+				t.dims = append(t.dims, int64(f.varint))
+			}
+		case 2:
+			t.dataType = int32(f.varint)
+		case 4:
+			if f.typ == protowire.BytesType {
+				for rest := f.bytes; len(rest) > 0; {
+					v, n := protowire.ConsumeFixed32(rest)
+					if n < 0 {
+						return t, protowire.ParseError(n)
+					}
+					t.floatData = append(t.floatData, math.Float32frombits(v))
+					rest = rest[n:]
+				}
+			} else {
+				t.floatData = append(t.floatData, math.Float32frombits(uint32(f.fixed)))
+			}
+		case 8:
+			t.name = string(f.bytes)
+		case 9:
+			t.rawData = f.bytes
+		case 10:
+			if f.typ == protowire.BytesType {
+				for rest := f.bytes; len(rest) > 0; {
+					v, n := protowire.ConsumeFixed64(rest)
+					if n < 0 {
+						return t, protowire.ParseError(n)
+					}
+					t.doubleData = append(t.doubleData, math.Float64frombits(v))
+					rest = rest[n:]
+				}
+			} else {
+				t.doubleData = append(t.doubleData, math.Float64frombits(f.fixed))
+			}
+		}
+	}
+	return t, nil
+}
+
+// scalar returns the single element held by a tensor.
+func (t *tensorProto) scalar() (float64, error) {
+	for _, d := range t.dims {
+		if d != 1 {
+			return 0, fmt.Errorf("tensor %q is not a scalar", t.name)
+		}
+	}
+	switch t.dataType {
+	case dataTypeFloat:
+		if len(t.floatData) == 1 {
+			return float64(t.floatData[0]), nil
+		}
+		if len(t.rawData) == 4 {
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(t.rawData))), nil
+		}
+	case dataTypeDouble:
+		if len(t.doubleData) == 1 {
+			return t.doubleData[0], nil
+		}
+		if len(t.rawData) == 8 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(t.rawData)), nil
+		}
+	default:
+		return 0, fmt.Errorf("tensor %q has unsupported data type %d", t.name, t.dataType)
+	}
+	return 0, fmt.Errorf("tensor %q does not hold exactly one element", t.name)
+}
+
+func unmarshalValueInfo(b []byte) (valueInfoProto, error) {
+	fields, err := decodeFields(b)
+	if err != nil {
+		return valueInfoProto{}, err
+	}
+	var v valueInfoProto
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			v.name = string(f.bytes)
+		case 2:
+			// TypeProto.tensor_type.elem_type
+			typ, err := decodeFields(f.bytes)
+			if err != nil {
+				return v, err
+			}
+			for _, tf := range typ {
+				if tf.num != 1 {
+					continue
+				}
+				tensor, err := decodeFields(tf.bytes)
+				if err != nil {
+					return v, err
+				}
+				for _, ef := range tensor {
+					if ef.num == 1 {
+						v.elemType = int32(ef.varint)
+					}
+				}
+			}
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,45 @@
+// Package optim provides utilities for training models built from
+// micrograd values.
+package optim
+
+import (
+	"math"
+
+	"microgograd/micrograd"
+)
+
+// GradNorm returns the L2 norm of the gradients of params, taken together as
+// a single vector.
+func GradNorm[K micrograd.BaseNumeric](params []*micrograd.Value[K]) K {
+	var sum float64
+	for _, p := range params {
+		g := float64(p.GetGradient())
+		sum += g * g
+	}
+	return K(math.Sqrt(sum))
+}
+
+// ClipGradNorm rescales the gradients of params so that their combined L2
+// norm is at most maxNorm, preserving their direction. Gradients are left
+// untouched when the norm is already within bounds. It returns the norm
+// before clipping.
+func ClipGradNorm[K micrograd.BaseNumeric](params []*micrograd.Value[K], maxNorm K) K {
+	norm := GradNorm(params)
+	if norm > maxNorm {
+		scale := maxNorm / norm
+		for _, p := range params {
+			p.SetGradient(p.GetGradient() * scale)
+		}
+	}
+	return norm
+}
+
+// ClipGradValue clamps every gradient of params to the range [-limit,
+// limit]. It returns the combined L2 norm of the gradients before clipping.
+func ClipGradValue[K micrograd.BaseNumeric](params []*micrograd.Value[K], limit K) K {
+	norm := GradNorm(params)
+	for _, p := range params {
+		p.SetGradient(min(max(p.GetGradient(), -limit), limit))
+	}
+	return norm
+}
@@ -0,0 +1,87 @@
+package optim
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"microgograd/micrograd"
+)
+
+func params[K micrograd.BaseNumeric](grads ...K) []*micrograd.Value[K] {
+	ps := make([]*micrograd.Value[K], len(grads))
+	for i, g := range grads {
+		ps[i] = micrograd.NewValue[K](0, micrograd.WithGradient(g))
+	}
+	return ps
+}
+
+func gradients[K micrograd.BaseNumeric](ps []*micrograd.Value[K]) []K {
+	gs := make([]K, len(ps))
+	for i, p := range ps {
+		gs[i] = p.GetGradient()
+	}
+	return gs
+}
+
+func TestGradNorm(t *testing.T) {
+	assert.Equal(t, 13.0, GradNorm(params(3.0, -4.0, 12.0)))
+	assert.Equal(t, float32(5), GradNorm(params[float32](3, 4)))
+	assert.Equal(t, 0.0, GradNorm[float64](nil))
+}
+
+func TestClipGradNorm(t *testing.T) {
+	t.Run("rescales large gradients", func(t *testing.T) {
+		ps := params(3.0, -4.0)
+
+		norm := ClipGradNorm(ps, 1)
+
+		assert.Equal(t, 5.0, norm)
+		assert.InDeltaSlice(t, []float64{0.6, -0.8}, gradients(ps), 1e-12)
+		assert.InDelta(t, 1.0, GradNorm(ps), 1e-12)
+	})
+
+	t.Run("leaves small gradients alone", func(t *testing.T) {
+		ps := params(0.3, -0.4)
+
+		norm := ClipGradNorm(ps, 1)
+
+		assert.InDelta(t, 0.5, norm, 1e-12)
+		assert.Equal(t, []float64{0.3, -0.4}, gradients(ps))
+	})
+
+	t.Run("float32", func(t *testing.T) {
+		ps := params[float32](30, 40)
+
+		norm := ClipGradNorm(ps, 5)
+
+		assert.Equal(t, float32(50), norm)
+		assert.InDeltaSlice(t, []float32{3, 4}, gradients(ps), 1e-5)
+	})
+
+	t.Run("after backward", func(t *testing.T) {
+		w := micrograd.NewValue(10.0)
+		b := micrograd.NewValue(-5.0)
+		x := micrograd.NewValue(3.0, micrograd.WithRequiresGrad(false))
+		loss := w.Mul(x).Add(b).Pow(2)
+		loss.Backward()
+		ps := []*micrograd.Value[float64]{w, b}
+
+		norm := ClipGradNorm(ps, 1)
+
+		// dloss/dw = 2*25*3, dloss/db = 2*25
+		assert.InDelta(t, 50*math.Sqrt(10), norm, 1e-9)
+		assert.InDelta(t, 1.0, GradNorm(ps), 1e-12)
+		assert.InDelta(t, 3.0, w.GetGradient()/b.GetGradient(), 1e-12)
+	})
+}
+
+func TestClipGradValue(t *testing.T) {
+	ps := params(3.0, -4.0, 0.5)
+
+	norm := ClipGradValue(ps, 1)
+
+	assert.InDelta(t, math.Sqrt(25.25), norm, 1e-12)
+	assert.Equal(t, []float64{1, -1, 0.5}, gradients(ps))
+}
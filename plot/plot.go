@@ -52,10 +52,6 @@ func nodeID[K micrograd.BaseNumeric](node micrograd.Numeric[K]) string {
 	return fmt.Sprintf("%p", node)
 }
 
-func pairToSlice[K micrograd.BaseNumeric](p micrograd.Pair[micrograd.Numeric[K]]) []micrograd.Numeric[K] {
-	return []micrograd.Numeric[K]{p.X(), p.Y()}
-}
-
 // collectNodes collects all nodes in topological order
 func collectNodes[K micrograd.BaseNumeric](node micrograd.Numeric[K], visited map[string]bool, nodes *[]micrograd.Numeric[K]) {
 	id := nodeID(node)
@@ -64,10 +60,8 @@ func collectNodes[K micrograd.BaseNumeric](node micrograd.Numeric[K], visited ma
 	}
 	visited[id] = true
 
-	for _, child := range pairToSlice(node.GetChildren()) {
-		if child != nil {
-			collectNodes(child, visited, nodes)
-		}
+	for _, child := range node.GetChildren() {
+		collectNodes(child, visited, nodes)
 	}
 
 	*nodes = append(*nodes, node)
@@ -106,14 +100,20 @@ func dotFromValue[K micrograd.BaseNumeric](node micrograd.Numeric[K], cfg *plotC
 		if op := n.GetOperation(); op != micrograd.UNSET {
 			// Create operation node
 			opNodeID := fmt.Sprintf("%s_op", nodeID(n))
+			label := op.String()
+			fontSize := "24"
+			if len([]rune(label)) > 1 {
+				// Named functions such as tanh need to fit in the fixed-size ellipse
+				fontSize = "14"
+			}
 			opNode := g.Node(opNodeID).
-				Attr("label", string(rune(op))).
+				Attr("label", label).
 				Attr("shape", "ellipse").
 				Attr("style", "filled").
 				Attr("fillcolor", "#f0f0f0").
 				Attr("width", "0.8").
 				Attr("height", "0.8").
-				Attr("fontsize", "24").
+				Attr("fontsize", fontSize).
 				Attr("penwidth", "2").
 				Attr("fixedsize", "true").
 				Attr("margin", "0.2").
@@ -130,16 +130,14 @@ func dotFromValue[K micrograd.BaseNumeric](node micrograd.Numeric[K], cfg *plotC
 				Attr("data-target", nodeID(n))
 
 			// Connect children to operation node
-			for _, child := range pairToSlice(n.GetChildren()) {
-				if child != nil {
-					g.Edge(nodeMap[nodeID(child)], opNode).
-						Attr("color", "#666666").
-						Attr("penwidth", "1.5").
-						Attr("class", "edge").
-						Attr("id", fmt.Sprintf("edge_%s_%s", nodeID(child), opNodeID)).
-						Attr("data-source", nodeID(child)).
-						Attr("data-target", opNodeID)
-				}
+			for _, child := range n.GetChildren() {
+				g.Edge(nodeMap[nodeID(child)], opNode).
+					Attr("color", "#666666").
+					Attr("penwidth", "1.5").
+					Attr("class", "edge").
+					Attr("id", fmt.Sprintf("edge_%s_%s", nodeID(child), opNodeID)).
+					Attr("data-source", nodeID(child)).
+					Attr("data-target", opNodeID)
 			}
 		}
 	}
@@ -90,4 +90,66 @@ func TestDOTGeneration(t *testing.T) {
 		// Check for edges
 		assert.True(t, strings.Contains(dot, "->"), "DOT should contain edges")
 	})
+
+	t.Run("unary operation nodes", func(t *testing.T) {
+		a := micrograd.NewValue(0.5, micrograd.WithName("a"))
+		b := a.Tanh().(*micrograd.Value[float64])
+		b.SetName("b")
+
+		cfg := &plotConfig[float64]{
+			labelFunc: defaultNodeLabel[float64],
+		}
+		dot := dotFromValue(b, cfg)
+
+		assert.Contains(t, dot, `label="tanh"`)
+		assert.Equal(t, 2, strings.Count(dot, "->"), "unary op should have one input edge and one output edge")
+	})
+
+	t.Run("n-ary operation nodes", func(t *testing.T) {
+		inputs := make([]micrograd.Numeric[float64], 5)
+		for i := range inputs {
+			inputs[i] = micrograd.NewValue(float64(i))
+		}
+		sum := micrograd.Sum(inputs...)
+
+		cfg := &plotConfig[float64]{
+			labelFunc: defaultNodeLabel[float64],
+		}
+		dot := dotFromValue(sum, cfg)
+
+		assert.Equal(t, 1, strings.Count(dot, `shape="ellipse"`), "sum should render as a single op node")
+		assert.Equal(t, 6, strings.Count(dot, "->"))
+	})
+}
+
+type clamp struct{}
+
+func (clamp) Name() string   { return "plot.clamp" }
+func (clamp) Symbol() string { return "clamp" }
+func (clamp) Arity() int     { return 1 }
+
+func (clamp) Forward(inputs []float64) float64 {
+	return min(max(inputs[0], -1), 1)
+}
+
+func (clamp) Gradients(inputs []float64, _ float64) []float64 {
+	if inputs[0] < -1 || inputs[0] > 1 {
+		return []float64{0}
+	}
+	return []float64{1}
+}
+
+var _ = micrograd.MustRegisterOp[float64](clamp{})
+
+func TestDOTGeneration_CustomOp(t *testing.T) {
+	a := micrograd.NewValue(2.0, micrograd.WithName("a"))
+	out := micrograd.Apply[float64](clamp{}, a)
+
+	cfg := &plotConfig[float64]{
+		labelFunc: defaultNodeLabel[float64],
+	}
+	dot := dotFromValue(out, cfg)
+
+	assert.Contains(t, dot, `label="clamp"`)
+	assert.Equal(t, 2, strings.Count(dot, "->"))
 }